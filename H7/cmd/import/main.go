@@ -0,0 +1,76 @@
+// Command import loads books from a CSV, JSON or NDJSON file the same way
+// POST /api/v1/books/import does and prints the report as JSON.
+//
+//	go run ./cmd/import [-format csv|json|ndjson] [-dry-run] books.csv
+//
+// The file is read from stdin when it is -, -format is then required.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/hansandika/internal/app/book"
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/pkg/util"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	format := flag.String("format", "", "csv, json or ndjson, guessed from the file name when empty")
+	dryRun := flag.Bool("dry-run", false, "validate every row without creating books")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: import [-format csv|json|ndjson] [-dry-run] file")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	input := &dto.BookImportRequest{Format: *format, DryRun: *dryRun}
+	if input.Format == "" {
+		input.Format = book.ImportFormat(path, "")
+	}
+	if input.Format == "" {
+		log.Fatal("unknown import format, pass -format csv, json or ndjson")
+	}
+	if err := util.NewValidator().Validate(input); err != nil {
+		log.Fatal(err)
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	godotenv.Load()
+	report, errs := book.NewUsecase(factory.NewFactory()).ImportBooks(r, input)
+	if errs != nil {
+		log.Fatal(errs.ErrorMessage)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "%d rows: %d created, %d valid, %d invalid, %d duplicates, %d failed\n",
+		report.Total, report.Created, report.Valid, report.Invalid, report.Duplicates, report.Failed)
+
+	if report.Invalid > 0 || report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -20,6 +20,9 @@ func initDB() *gorm.DB {
 		fmt.Println("Ayam")
 		panic(err)
 	}
+	if err := Migrate(db); err != nil {
+		panic(err)
+	}
 	fmt.Println("Connected to DB")
 	return db
 }
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(db *gorm.DB) error {
+	// accounts created before email verification existed count as verified,
+	// they could no longer log in once verification is required
+	verifyExisting := db.HasTable(&model.User{}) && !db.Dialect().HasColumn("users", "email_verified_at")
+
+	err := db.AutoMigrate(
+		&model.User{},
+		&model.Book{},
+		&model.BookCopy{},
+		&model.Loan{},
+		&model.Hold{},
+		&model.FineEntry{},
+		&model.Review{},
+		&model.ReviewVote{},
+		&model.Author{},
+		&model.Category{},
+		&model.Tag{},
+		&model.RefreshToken{},
+		&model.PasswordResetToken{},
+		&model.LoginThrottle{},
+		&model.RecoveryCode{},
+		&model.APIKey{},
+		&model.Session{},
+	).Error
+	if err != nil {
+		return err
+	}
+
+	if verifyExisting {
+		if err := markEmailsVerified(db); err != nil {
+			return err
+		}
+	}
+
+	if err := addBookFulltextIndex(db); err != nil {
+		return err
+	}
+	if err := markActiveHolds(db); err != nil {
+		return err
+	}
+	if err := purgeDeletedAuthors(db); err != nil {
+		return err
+	}
+	return linkBookAuthors(db)
+}
+
+// markEmailsVerified treats the address of every account as verified since
+// the account was created.
+func markEmailsVerified(db *gorm.DB) error {
+	return db.Model(&model.User{}).
+		Where("email_verified_at IS NULL").
+		UpdateColumn("email_verified_at", gorm.Expr("created_at")).Error
+}
+
+// markActiveHolds sets the active flag of holds queued before it existed,
+// the unique index on active holds only covers flagged rows.
+func markActiveHolds(db *gorm.DB) error {
+	return db.Model(&model.Hold{}).
+		Where("status IN (?) AND active IS NULL", []string{constant.HOLD_STATUS_WAITING, constant.HOLD_STATUS_READY}).
+		UpdateColumn("active", true).Error
+}
+
+// addBookFulltextIndex creates the index used by the MySQL book search. gorm
+// can't declare FULLTEXT indexes, and other databases fall back to the in
+// memory search.
+func addBookFulltextIndex(db *gorm.DB) error {
+	if db.Dialect().GetName() != "mysql" {
+		return nil
+	}
+
+	var count int
+	err := db.Table("information_schema.statistics").
+		Where("table_schema = DATABASE() AND table_name = ? AND index_name = ?", "books", "idx_books_fulltext").
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return db.Exec("ALTER TABLE books ADD FULLTEXT INDEX idx_books_fulltext (title, description, author)").Error
+}
+
+// purgeDeletedAuthors removes the authors soft deleted before deletes became
+// permanent, they would otherwise keep their name keys taken.
+func purgeDeletedAuthors(db *gorm.DB) error {
+	return db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Author{}).Error
+}
+
+// linkBookAuthors turns the author string of every book without author links
+// into an author record, reusing records whose name normalizes to the same
+// key.
+func linkBookAuthors(db *gorm.DB) error {
+	var books []model.Book
+	err := db.Where("author <> '' AND id NOT IN (?)", db.Table("book_authors").Select("book_id").SubQuery()).
+		Find(&books).Error
+	if err != nil || len(books) == 0 {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i := range books {
+			name := strings.TrimSpace(books[i].Author)
+			key := model.AuthorNameKey(name)
+			if key == "" {
+				continue
+			}
+
+			var author model.Author
+			if err := tx.Where(model.Author{NameKey: key}).Attrs(model.Author{Name: name}).FirstOrCreate(&author).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&books[i]).Association("Authors").Append(&author).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
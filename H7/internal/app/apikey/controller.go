@@ -0,0 +1,79 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) CreateAPIKey(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.NewAPIKey
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.CreateAPIKey(principal, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Create API key success, the key is only shown once", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetAPIKeys(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAPIKeys(principal.UserID)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get API keys success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) RevokeAPIKey(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.RevokeAPIKey(principal.UserID, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Revoke API key success", res).SendSuccessResponse(c)
+}
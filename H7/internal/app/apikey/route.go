@@ -0,0 +1,13 @@
+package apikey
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	e.Use(c.authMiddleware, middleware.RejectAPIKeys())
+	e.POST("", c.CreateAPIKey)
+	e.GET("", c.GetAPIKeys)
+	e.DELETE("/:id", c.RevokeAPIKey)
+}
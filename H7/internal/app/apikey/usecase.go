@@ -0,0 +1,137 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CreateAPIKey(principal *jwtUtil.Principal, input *dto.NewAPIKey) (*dto.APIKeyResponseWithKey, *response.ErrorResponse)
+	GetAPIKeys(userId uint) ([]*dto.APIKeyResponse, *response.ErrorResponse)
+	RevokeAPIKey(userId uint, id int) (*dto.APIKeyResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	APIKeyRepository repository.APIKeyRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		APIKeyRepository: f.APIKeyRepository,
+	}
+}
+
+func (u *usecase) CreateAPIKey(principal *jwtUtil.Principal, input *dto.NewAPIKey) (*dto.APIKeyResponseWithKey, *response.ErrorResponse) {
+	var result *dto.APIKeyResponseWithKey
+
+	if principal.APIKeyID != 0 {
+		return result, response.NewErrorResponse(http.StatusForbidden, errors.New("API keys can't be used to create API keys"))
+	}
+
+	rawKey, prefix, err := jwtUtil.GenerateAPIKey()
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	ttl := util.GetenvDuration("API_KEY_DEFAULT_TTL", 90*24*time.Hour)
+	if input.ExpiresInDays > 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	key, err := u.APIKeyRepository.CreateAPIKey(&model.APIKey{
+		UserID:    principal.UserID,
+		Name:      input.Name,
+		Prefix:    prefix,
+		KeyHash:   jwtUtil.HashToken(rawKey),
+		Scopes:    strings.Join(input.Scopes, ","),
+		MFA:       principal.MFA,
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.APIKeyResponseWithKey{
+		APIKeyResponse: dto.APIKeyResponse{
+			ID:         int(key.ID),
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     key.ScopeList(),
+			CreatedAt:  key.CreatedAt,
+			ExpiresAt:  key.ExpiresAt,
+			LastUsedAt: key.LastUsedAt,
+			LastUsedIP: key.LastUsedIP,
+			RevokedAt:  key.RevokedAt,
+		},
+		Key: rawKey,
+	}
+	return result, nil
+}
+
+func (u *usecase) GetAPIKeys(userId uint) ([]*dto.APIKeyResponse, *response.ErrorResponse) {
+	var result []*dto.APIKeyResponse
+
+	keys, err := u.APIKeyRepository.GetUserAPIKeys(userId)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	for _, key := range keys {
+		result = append(result, &dto.APIKeyResponse{
+			ID:         int(key.ID),
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     key.ScopeList(),
+			CreatedAt:  key.CreatedAt,
+			ExpiresAt:  key.ExpiresAt,
+			LastUsedAt: key.LastUsedAt,
+			LastUsedIP: key.LastUsedIP,
+			RevokedAt:  key.RevokedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (u *usecase) RevokeAPIKey(userId uint, id int) (*dto.APIKeyResponse, *response.ErrorResponse) {
+	var result *dto.APIKeyResponse
+
+	key, err := u.APIKeyRepository.GetUserAPIKeyById(userId, id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("API key not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if key.RevokedAt == nil {
+		if err := u.APIKeyRepository.RevokeAPIKey(key); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	result = &dto.APIKeyResponse{
+		ID:         int(key.ID),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		RevokedAt:  key.RevokedAt,
+	}
+	return result, nil
+}
@@ -1,21 +1,27 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
 	"github.com/hansandika/pkg/util/response"
 	"github.com/labstack/echo"
 )
 
 type controller struct {
-	usecase UsecaseInterface
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
 }
 
 func NewController(f *factory.Factory) *controller {
 	return &controller{
-		usecase: NewUsecase(f),
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
 	}
 }
 
@@ -29,10 +35,16 @@ func (co *controller) LoginByEmailAndPassword(c echo.Context) error {
 		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
 	}
 
-	res, err := co.usecase.LoginByEmailAndPassword(&input)
+	res, err := co.usecase.LoginByEmailAndPassword(&input, &dto.ClientInfo{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
 	if err != nil {
 		return err.SendErrorResponse(c)
 	}
+	if res.MFARequired {
+		return response.NewSuccessResponse(http.StatusOK, "Two-factor authentication required", res).SendSuccessResponse(c)
+	}
 	return response.NewSuccessResponse(http.StatusOK, "Login success", res).SendSuccessResponse(c)
 }
 
@@ -52,3 +64,219 @@ func (co *controller) RegisterUserByEmailAndPassword(c echo.Context) error {
 	}
 	return response.NewSuccessResponse(http.StatusCreated, "Register success", res).SendSuccessResponse(c)
 }
+
+func (co *controller) RefreshToken(c echo.Context) error {
+	var input dto.RefreshTokenRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, err := co.usecase.RefreshToken(&input)
+	if err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Refresh token success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) Logout(c echo.Context) error {
+	var input dto.RefreshTokenRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := co.usecase.Logout(&input); err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Logout success", nil).SendSuccessResponse(c)
+}
+
+func (co *controller) ForgotPassword(c echo.Context) error {
+	var input dto.ForgotPasswordRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := co.usecase.ForgotPassword(&input); err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "If the email is registered, a reset link has been sent", nil).SendSuccessResponse(c)
+}
+
+func (co *controller) ResetPassword(c echo.Context) error {
+	var input dto.ResetPasswordRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := co.usecase.ResetPassword(&input); err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Reset password success", nil).SendSuccessResponse(c)
+}
+
+func (co *controller) VerifyEmail(c echo.Context) error {
+	var input dto.VerifyEmailRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, err := co.usecase.VerifyEmail(&input)
+	if err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Verify email success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) ResendVerification(c echo.Context) error {
+	var input dto.ResendVerificationRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := co.usecase.ResendVerification(&input); err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "If the email needs verification, a new link has been sent", nil).SendSuccessResponse(c)
+}
+
+func (co *controller) VerifyMFA(c echo.Context) error {
+	var input dto.MFAVerifyRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, err := co.usecase.VerifyMFA(&input, &dto.ClientInfo{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Login success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) EnrollTOTP(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.EnrollTOTP(int(principal.UserID))
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Two-factor enrollment started", res).SendSuccessResponse(c)
+}
+
+func (co *controller) ConfirmTOTP(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.TOTPCodeRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.ConfirmTOTP(int(principal.UserID), &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Two-factor authentication enabled", res).SendSuccessResponse(c)
+}
+
+func (co *controller) DisableTOTP(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.TOTPCodeRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if errs := co.usecase.DisableTOTP(int(principal.UserID), &input); errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Two-factor authentication disabled", nil).SendSuccessResponse(c)
+}
+
+func (co *controller) GetSessions(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetSessions(principal)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get sessions success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) RevokeSession(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	if errs := co.usecase.RevokeSession(principal, id); errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Revoke session success", nil).SendSuccessResponse(c)
+}
+
+func (co *controller) RevokeAllSessions(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	if errs := co.usecase.RevokeAllSessions(principal); errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Logged out of all sessions", nil).SendSuccessResponse(c)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/hansandika/database"
@@ -11,14 +12,23 @@ import (
 	"github.com/hansandika/internal/factory"
 	"github.com/hansandika/internal/mocks"
 	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/mailer"
 	"github.com/labstack/echo"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	db             = database.GetConnection()
-	echoMock       = mocks.EchoMock{E: echo.New()}
-	f              = factory.Factory{UserRepository: repository.InitUserRepository(db)}
+	db       = database.GetConnection()
+	echoMock = mocks.EchoMock{E: echo.New()}
+	f        = factory.Factory{
+		UserRepository:               repository.InitUserRepository(db),
+		RefreshTokenRepository:       repository.InitRefreshTokenRepository(db),
+		PasswordResetTokenRepository: repository.InitPasswordResetTokenRepository(db),
+		LoginThrottleRepository:      repository.InitLoginThrottleRepository(db),
+		RecoveryCodeRepository:       repository.InitRecoveryCodeRepository(db),
+		SessionRepository:            repository.InitSessionRepository(db),
+		Mailer:                       mailer.NewOutboxMailer(os.TempDir(), "no-reply@localhost"),
+	}
 	controllerTest = NewController(&f)
 )
 
@@ -60,6 +70,7 @@ func TestAuthLoginByEmailAndPasswordSuccess(t *testing.T) {
 		asserts.Contains(body, "name")
 		asserts.Contains(body, "email")
 		asserts.Contains(body, "token")
+		asserts.Contains(body, "refresh_token")
 	}
 }
 
@@ -80,7 +91,7 @@ func TestAuthLoginByEmailAndPasswordFailed(t *testing.T) {
 	asserts := assert.New(t)
 	// testing
 	if asserts.NoError(controllerTest.LoginByEmailAndPassword(c)) {
-		asserts.Equal(400, rec.Code)
+		asserts.Equal(401, rec.Code)
 
 		body := rec.Body.String()
 		asserts.Contains(body, "Invalid email or password")
@@ -134,3 +145,23 @@ func TestAuthRegisterByEmailAndPasswordSuccess(t *testing.T) {
 		asserts.Contains(body, "Register success")
 	}
 }
+
+func TestAuthRefreshTokenInvalid(t *testing.T) {
+	payload, err := json.Marshal(&dto.RefreshTokenRequest{RefreshToken: "invalid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.SetPath("/api/v1/auth/refresh")
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	asserts := assert.New(t)
+	// testing
+	if asserts.NoError(controllerTest.RefreshToken(c)) {
+		asserts.Equal(401, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Invalid refresh token")
+	}
+}
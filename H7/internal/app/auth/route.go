@@ -1,10 +1,27 @@
 package auth
 
 import (
+	"github.com/hansandika/internal/middleware"
 	"github.com/labstack/echo"
 )
 
 func (c *controller) Route(e *echo.Group) {
+	canManageAccount := []echo.MiddlewareFunc{c.authMiddleware, middleware.RejectAPIKeys()}
+
 	e.POST("/login", c.LoginByEmailAndPassword)
 	e.POST("/signup", c.RegisterUserByEmailAndPassword)
+	e.POST("/refresh", c.RefreshToken)
+	e.POST("/logout", c.Logout)
+	e.GET("/email/verify", c.VerifyEmail)
+	e.POST("/email/verify", c.VerifyEmail)
+	e.POST("/email/resend", c.ResendVerification)
+	e.POST("/password/forgot", c.ForgotPassword)
+	e.POST("/password/reset", c.ResetPassword)
+	e.POST("/2fa/verify", c.VerifyMFA)
+	e.POST("/2fa/enroll", c.EnrollTOTP, canManageAccount...)
+	e.POST("/2fa/confirm", c.ConfirmTOTP, canManageAccount...)
+	e.DELETE("/2fa", c.DisableTOTP, canManageAccount...)
+	e.GET("/sessions", c.GetSessions, canManageAccount...)
+	e.DELETE("/sessions", c.RevokeAllSessions, canManageAccount...)
+	e.DELETE("/sessions/:id", c.RevokeSession, canManageAccount...)
 }
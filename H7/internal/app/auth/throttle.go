@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hansandika/pkg/util"
+)
+
+var (
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+// getDummyHash is compared against when the email is unknown, so that a login
+// for a missing account costs as much as one with a wrong password.
+func getDummyHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = util.HashPassword("dummy-password-for-timing")
+	})
+	return dummyHash
+}
+
+func getenvInt(key string, fallback int) int {
+	val, err := strconv.Atoi(util.Getenv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// lockoutDuration doubles the lock for every failure past the limit, starting
+// at LOGIN_LOCKOUT_BASE and never exceeding LOGIN_LOCKOUT_MAX.
+func lockoutDuration(failures, limit int) time.Duration {
+	if failures < limit {
+		return 0
+	}
+	base := util.GetenvDuration("LOGIN_LOCKOUT_BASE", time.Minute)
+	max := util.GetenvDuration("LOGIN_LOCKOUT_MAX", time.Hour)
+
+	duration := base
+	for i := limit; i < failures && duration < max; i++ {
+		duration *= 2
+	}
+	if duration > max {
+		duration = max
+	}
+	return duration
+}
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/hansandika/pkg/constant"
 
@@ -11,22 +13,49 @@ import (
 	"github.com/hansandika/internal/model"
 	jwtUtil "github.com/hansandika/internal/pkg/util"
 	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/mailer"
 	"github.com/hansandika/pkg/util"
 	"github.com/hansandika/pkg/util/response"
+	"github.com/jinzhu/gorm"
 )
 
 type usecase struct {
-	UserRepository repository.UserRepositoryInterface
+	UserRepository               repository.UserRepositoryInterface
+	RefreshTokenRepository       repository.RefreshTokenRepositoryInterface
+	PasswordResetTokenRepository repository.PasswordResetTokenRepositoryInterface
+	LoginThrottleRepository      repository.LoginThrottleRepositoryInterface
+	RecoveryCodeRepository       repository.RecoveryCodeRepositoryInterface
+	SessionRepository            repository.SessionRepositoryInterface
+	Mailer                       mailer.Mailer
 }
 
 type UsecaseInterface interface {
 	RegisterUserByEmailAndPassword(input *dto.NewUser) (*dto.UserResponse, *response.ErrorResponse)
-	LoginByEmailAndPassword(input *dto.UserCredential) (*dto.UserResponseWithToken, *response.ErrorResponse)
+	LoginByEmailAndPassword(input *dto.UserCredential, client *dto.ClientInfo) (*dto.UserResponseWithToken, *response.ErrorResponse)
+	RefreshToken(input *dto.RefreshTokenRequest) (*dto.TokenResponse, *response.ErrorResponse)
+	Logout(input *dto.RefreshTokenRequest) *response.ErrorResponse
+	VerifyEmail(input *dto.VerifyEmailRequest) (*dto.UserResponse, *response.ErrorResponse)
+	ResendVerification(input *dto.ResendVerificationRequest) *response.ErrorResponse
+	ForgotPassword(input *dto.ForgotPasswordRequest) *response.ErrorResponse
+	ResetPassword(input *dto.ResetPasswordRequest) *response.ErrorResponse
+	VerifyMFA(input *dto.MFAVerifyRequest, client *dto.ClientInfo) (*dto.UserResponseWithToken, *response.ErrorResponse)
+	EnrollTOTP(userId int) (*dto.TOTPEnrollmentResponse, *response.ErrorResponse)
+	ConfirmTOTP(userId int, input *dto.TOTPCodeRequest) (*dto.RecoveryCodesResponse, *response.ErrorResponse)
+	DisableTOTP(userId int, input *dto.TOTPCodeRequest) *response.ErrorResponse
+	GetSessions(principal *jwtUtil.Principal) (*[]dto.SessionResponse, *response.ErrorResponse)
+	RevokeSession(principal *jwtUtil.Principal, sessionId int) *response.ErrorResponse
+	RevokeAllSessions(principal *jwtUtil.Principal) *response.ErrorResponse
 }
 
 func NewUsecase(f *factory.Factory) UsecaseInterface {
 	return &usecase{
-		UserRepository: f.UserRepository,
+		UserRepository:               f.UserRepository,
+		RefreshTokenRepository:       f.RefreshTokenRepository,
+		PasswordResetTokenRepository: f.PasswordResetTokenRepository,
+		LoginThrottleRepository:      f.LoginThrottleRepository,
+		RecoveryCodeRepository:       f.RecoveryCodeRepository,
+		SessionRepository:            f.SessionRepository,
+		Mailer:                       f.Mailer,
 	}
 }
 
@@ -41,6 +70,10 @@ func (u *usecase) RegisterUserByEmailAndPassword(input *dto.NewUser) (*dto.UserR
 		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Email already exists"))
 	}
 
+	if err := util.ValidatePassword(input.Password, input.Email); err != nil {
+		return result, response.NewErrorResponse(http.StatusBadRequest, err)
+	}
+
 	hashedPassword, err := util.HashPassword(input.Password)
 	if err != nil {
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
@@ -50,47 +83,690 @@ func (u *usecase) RegisterUserByEmailAndPassword(input *dto.NewUser) (*dto.UserR
 		Name:     input.Name,
 		Email:    input.Email,
 		Password: hashedPassword,
+		Roles:    constant.ROLE_MEMBER,
 	})
 	if err != nil {
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
+	// a failed delivery is not fatal, the user can ask for the link again
+	_ = u.sendVerificationEmail(data)
+
 	result = &dto.UserResponse{
-		ID:    int(data.ID),
-		Name:  data.Name,
-		Email: data.Email,
+		ID:            int(data.ID),
+		Name:          data.Name,
+		Email:         data.Email,
+		EmailVerified: data.EmailVerifiedAt != nil,
+		Roles:         data.RoleList(),
 	}
 
 	return result, nil
 }
 
-func (u *usecase) LoginByEmailAndPassword(input *dto.UserCredential) (*dto.UserResponseWithToken, *response.ErrorResponse) {
+func (u *usecase) LoginByEmailAndPassword(input *dto.UserCredential, client *dto.ClientInfo) (*dto.UserResponseWithToken, *response.ErrorResponse) {
 	var result *dto.UserResponseWithToken
 
+	accountKey := model.AccountThrottleKey(input.Email)
+	ipKey := model.IPThrottleKey(client.IP)
+	for _, key := range []string{accountKey, ipKey} {
+		throttle, err := u.LoginThrottleRepository.GetLoginThrottle(key)
+		if err != nil && err != constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		if err == nil && throttle.IsLocked(time.Now()) {
+			return result, response.NewErrorResponse(http.StatusTooManyRequests, errors.New("Too many failed login attempts, try again later"))
+		}
+	}
+
 	user, err := u.UserRepository.GetUserByEmail(input.Email)
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	hashedPassword := getDummyHash()
+	if err == nil {
+		hashedPassword = user.Password
+	}
+
+	// unknown emails and wrong passwords take the same path and get the same answer
+	if !util.CompareHashPassword(input.Password, hashedPassword) || err != nil {
+		if err := u.recordLoginFailure(accountKey, ipKey); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid email or password"))
+	}
+
+	if err := u.LoginThrottleRepository.ResetLoginThrottle(accountKey); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	// the plain password is only available here, so outdated hashes are upgraded on login
+	if util.NeedsRehash(user.Password) {
+		if hashedPassword, err := util.HashPassword(input.Password); err == nil {
+			user.Password = hashedPassword
+			if user, err = u.UserRepository.UpdateUser(user); err != nil {
+				return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+			}
+		}
+	}
+
+	if user.EmailVerifiedAt == nil && util.Getenv("REQUIRE_EMAIL_VERIFICATION", "false") == "true" {
+		return result, response.NewErrorResponse(http.StatusForbidden, errors.New("Email address is not verified"))
+	}
+
+	// accounts with 2FA only get a short lived token that can be exchanged at /2fa/verify
+	if user.TOTPEnabledAt != nil {
+		mfaToken, err := jwtUtil.GeneratePurposeToken(user.ID, user.Email, jwtUtil.PURPOSE_MFA, util.GetenvDuration("MFA_TOKEN_TTL", 5*time.Minute))
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, errors.New("error when genereating a new token"))
+		}
+		result = &dto.UserResponseWithToken{
+			UserResponse: dto.UserResponse{
+				ID:            int(user.ID),
+				Name:          user.Name,
+				Email:         user.Email,
+				EmailVerified: user.EmailVerifiedAt != nil,
+				Roles:         user.RoleList(),
+			},
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+		return result, nil
+	}
+
+	result, err = u.startSession(user, false, client)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, errors.New("error when genereating a new token"))
+	}
+	return result, nil
+}
+
+func (u *usecase) VerifyMFA(input *dto.MFAVerifyRequest, client *dto.ClientInfo) (*dto.UserResponseWithToken, *response.ErrorResponse) {
+	var result *dto.UserResponseWithToken
+
+	claims, err := jwtUtil.ParsePurposeToken(input.MFAToken, jwtUtil.PURPOSE_MFA)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid or expired MFA token"))
+	}
+
+	user, err := u.UserRepository.GetUserById(int(claims.UserId))
 	if err != nil {
 		if err == constant.RECORD_NOT_FOUND {
-			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+			return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid or expired MFA token"))
 		}
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
+	if user.TOTPEnabledAt == nil {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Two-factor authentication is not enabled"))
+	}
 
-	if !util.CompareHashPassword(input.Password, user.Password) {
-		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid email or password"))
+	// codes are guessed against the same lockout as passwords
+	accountKey := model.AccountThrottleKey(user.Email)
+	throttle, err := u.LoginThrottleRepository.GetLoginThrottle(accountKey)
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if err == nil && throttle.IsLocked(time.Now()) {
+		return result, response.NewErrorResponse(http.StatusTooManyRequests, errors.New("Too many failed login attempts, try again later"))
 	}
 
-	token, err := jwtUtil.GenerateJWT(user.ID)
+	ok, err := u.verifySecondFactor(user, input.Code)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if !ok {
+		if err := u.recordLoginFailure(accountKey, model.IPThrottleKey(client.IP)); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid authentication code"))
+	}
+
+	if err := u.LoginThrottleRepository.ResetLoginThrottle(accountKey); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result, err = u.startSession(user, true, client)
 	if err != nil {
 		return result, response.NewErrorResponse(http.StatusInternalServerError, errors.New("error when genereating a new token"))
 	}
+	return result, nil
+}
+
+func (u *usecase) EnrollTOTP(userId int) (*dto.TOTPEnrollmentResponse, *response.ErrorResponse) {
+	var result *dto.TOTPEnrollmentResponse
+
+	user, err := u.UserRepository.GetUserById(userId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if user.TOTPEnabledAt != nil {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Two-factor authentication is already enabled"))
+	}
 
-	result = &dto.UserResponseWithToken{
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if _, err := u.UserRepository.UpdateUser(user); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.TOTPEnrollmentResponse{
+		Secret: secret,
+		URI:    util.TOTPURI(util.Getenv("TOTP_ISSUER", "Library"), user.Email, secret),
+	}
+	return result, nil
+}
+
+func (u *usecase) ConfirmTOTP(userId int, input *dto.TOTPCodeRequest) (*dto.RecoveryCodesResponse, *response.ErrorResponse) {
+	var result *dto.RecoveryCodesResponse
+
+	user, err := u.UserRepository.GetUserById(userId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if user.TOTPEnabledAt != nil {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Two-factor authentication is already enabled"))
+	}
+	if user.TOTPSecret == "" {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Two-factor enrollment has not been started"))
+	}
+
+	step, ok := util.ValidateTOTP(user.TOTPSecret, input.Code, time.Now())
+	if !ok {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid authentication code"))
+	}
+
+	codes := make([]string, 10)
+	hashes := make([]string, 10)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		codes[i] = code
+		hashes[i] = jwtUtil.HashToken(normalizeRecoveryCode(code))
+	}
+	if err := u.RecoveryCodeRepository.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.TOTPLastStep = step
+	if _, err := u.UserRepository.UpdateUser(user); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.RecoveryCodesResponse{RecoveryCodes: codes}
+	return result, nil
+}
+
+func (u *usecase) DisableTOTP(userId int, input *dto.TOTPCodeRequest) *response.ErrorResponse {
+	user, err := u.UserRepository.GetUserById(userId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if user.TOTPEnabledAt == nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Two-factor authentication is not enabled"))
+	}
+	if user.RequiresMFA() {
+		return response.NewErrorResponse(http.StatusForbidden, errors.New("Two-factor authentication is mandatory for this account"))
+	}
+
+	ok, err := u.verifySecondFactor(user, input.Code)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if !ok {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid authentication code"))
+	}
+
+	if err := u.RecoveryCodeRepository.DeleteRecoveryCodes(user.ID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	if _, err := u.UserRepository.UpdateUser(user); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+// verifySecondFactor accepts either a TOTP code that was not used before or an
+// unused recovery code.
+func (u *usecase) verifySecondFactor(user *model.User, code string) (bool, error) {
+	if step, ok := util.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		return u.UserRepository.UseTOTPStep(user, step)
+	}
+	return u.RecoveryCodeRepository.UseRecoveryCode(user.ID, jwtUtil.HashToken(normalizeRecoveryCode(code)))
+}
+
+func (u *usecase) startSession(user *model.User, mfa bool, client *dto.ClientInfo) (*dto.UserResponseWithToken, error) {
+	familyId, err := jwtUtil.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := u.SessionRepository.CreateSession(&model.Session{
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := u.issueTokens(user, session.ID, familyId, mfa)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.UserResponseWithToken{
 		UserResponse: dto.UserResponse{
-			ID:    int(user.ID),
-			Name:  user.Name,
-			Email: user.Email,
+			ID:            int(user.ID),
+			Name:          user.Name,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerifiedAt != nil,
+			Roles:         user.RoleList(),
 		},
-		Token: token,
+		Token:                 tokens.Token,
+		RefreshToken:          tokens.RefreshToken,
+		MFAEnrollmentRequired: user.RequiresMFA() && user.TOTPEnabledAt == nil,
+	}, nil
+}
+
+func (u *usecase) recordLoginFailure(accountKey, ipKey string) error {
+	window := util.GetenvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute)
+	limits := map[string]int{
+		accountKey: getenvInt("LOGIN_MAX_ATTEMPTS", 5),
+		ipKey:      getenvInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+	}
+
+	for key, limit := range limits {
+		throttle, err := u.LoginThrottleRepository.RecordLoginFailure(key, window)
+		if err != nil {
+			return err
+		}
+		if duration := lockoutDuration(throttle.Failures, limit); duration > 0 {
+			if err := u.LoginThrottleRepository.LockLoginThrottle(throttle, time.Now().Add(duration)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (u *usecase) RefreshToken(input *dto.RefreshTokenRequest) (*dto.TokenResponse, *response.ErrorResponse) {
+	var result *dto.TokenResponse
+
+	token, err := u.RefreshTokenRepository.GetRefreshTokenByHash(jwtUtil.HashToken(input.RefreshToken))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid refresh token"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	// A refresh token that was already rotated is being replayed, so whoever
+	// holds this family can no longer be trusted.
+	if token.RevokedAt != nil {
+		if err := u.revokeTokenSession(token); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Refresh token reuse detected"))
+	}
+
+	if token.ExpiresAt.Before(time.Now()) {
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Refresh token expired"))
+	}
+
+	revoked, err := u.RefreshTokenRepository.RevokeRefreshToken(token)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if !revoked {
+		if err := u.revokeTokenSession(token); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Refresh token reuse detected"))
+	}
+
+	if token.SessionID == 0 {
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Session has been revoked"))
+	}
+	session, err := u.SessionRepository.GetSessionById(token.SessionID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Session has been revoked"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if session.RevokedAt != nil {
+		return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Session has been revoked"))
+	}
+
+	user, err := u.UserRepository.GetUserById(int(token.UserID))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid refresh token"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result, err = u.issueTokens(user, session.ID, token.FamilyID, token.MFA)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, errors.New("error when genereating a new token"))
+	}
+	return result, nil
+}
+
+func (u *usecase) Logout(input *dto.RefreshTokenRequest) *response.ErrorResponse {
+	token, err := u.RefreshTokenRepository.GetRefreshTokenByHash(jwtUtil.HashToken(input.RefreshToken))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return response.NewErrorResponse(http.StatusUnauthorized, errors.New("Invalid refresh token"))
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if err := u.revokeTokenSession(token); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+// revokeTokenSession ends the session a refresh token belongs to, together
+// with every token rotated from it.
+func (u *usecase) revokeTokenSession(token *model.RefreshToken) error {
+	if err := u.RefreshTokenRepository.RevokeTokenFamily(token.FamilyID); err != nil {
+		return err
+	}
+	if token.SessionID == 0 {
+		return nil
+	}
+	return u.SessionRepository.RevokeSession(&model.Session{Model: gorm.Model{ID: token.SessionID}})
+}
+
+func (u *usecase) GetSessions(principal *jwtUtil.Principal) (*[]dto.SessionResponse, *response.ErrorResponse) {
+	sessions, err := u.SessionRepository.GetUserSessions(principal.UserID)
+	if err != nil {
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result := []dto.SessionResponse{}
+	for _, session := range sessions {
+		result = append(result, dto.SessionResponse{
+			ID:         int(session.ID),
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == principal.SessionID,
+		})
+	}
+	return &result, nil
+}
+
+func (u *usecase) RevokeSession(principal *jwtUtil.Principal, sessionId int) *response.ErrorResponse {
+	session, err := u.SessionRepository.GetSessionById(uint(sessionId))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return response.NewErrorResponse(http.StatusNotFound, errors.New("Session not found"))
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if session.UserID != principal.UserID || session.RevokedAt != nil {
+		return response.NewErrorResponse(http.StatusNotFound, errors.New("Session not found"))
+	}
+
+	if err := u.SessionRepository.RevokeSession(session); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if err := u.RefreshTokenRepository.RevokeSessionRefreshTokens(session.ID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) RevokeAllSessions(principal *jwtUtil.Principal) *response.ErrorResponse {
+	if err := u.SessionRepository.RevokeUserSessions(principal.UserID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if err := u.RefreshTokenRepository.RevokeUserRefreshTokens(principal.UserID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) VerifyEmail(input *dto.VerifyEmailRequest) (*dto.UserResponse, *response.ErrorResponse) {
+	var result *dto.UserResponse
+	invalidToken := response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid or expired verification token"))
+
+	claims, err := jwtUtil.ParsePurposeToken(input.Token, jwtUtil.PURPOSE_EMAIL_VERIFICATION)
+	if err != nil {
+		return result, invalidToken
+	}
+
+	user, err := u.UserRepository.GetUserById(int(claims.UserId))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, invalidToken
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	// the link was sent to an address the account no longer uses
+	if user.Email != claims.Email {
+		return result, invalidToken
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if user, err = u.UserRepository.UpdateUser(user); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	result = &dto.UserResponse{
+		ID:            int(user.ID),
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Roles:         user.RoleList(),
 	}
 	return result, nil
 }
+
+// ResendVerification is throttled per account and, like ForgotPassword, does
+// not reveal whether the address is registered or already verified.
+func (u *usecase) ResendVerification(input *dto.ResendVerificationRequest) *response.ErrorResponse {
+	user, err := u.UserRepository.GetUserByEmail(input.Email)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	interval := util.GetenvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute)
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < interval {
+		return response.NewErrorResponse(http.StatusTooManyRequests, errors.New("Please wait before requesting another verification email"))
+	}
+
+	if err := u.sendVerificationEmail(user); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) sendVerificationEmail(user *model.User) error {
+	return SendVerificationEmail(u.Mailer, u.UserRepository, user)
+}
+
+// SendVerificationEmail mails user a link confirming its current address and
+// records when it was sent.
+func SendVerificationEmail(m mailer.Mailer, users repository.UserRepositoryInterface, user *model.User) error {
+	ttl := util.GetenvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	token, err := jwtUtil.GeneratePurposeToken(user.ID, user.Email, jwtUtil.PURPOSE_EMAIL_VERIFICATION, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/email/verify?token=%s", util.Getenv("APP_URL", "http://localhost:8080"), token)
+	err = m.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.Name, ttl, link),
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.VerificationSentAt = &now
+	_, err = users.UpdateUser(user)
+	return err
+}
+
+// ForgotPassword answers the same way whether or not the email is registered,
+// so it can't be used to find out which addresses have an account.
+func (u *usecase) ForgotPassword(input *dto.ForgotPasswordRequest) *response.ErrorResponse {
+	user, err := u.UserRepository.GetUserByEmail(input.Email)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if err := u.PasswordResetTokenRepository.InvalidateUserPasswordResetTokens(user.ID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	token, err := jwtUtil.GenerateRandomToken(32)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	ttl := util.GetenvDuration("PASSWORD_RESET_TTL", time.Hour)
+	_, err = u.PasswordResetTokenRepository.CreatePasswordResetToken(&model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: jwtUtil.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", util.Getenv("APP_URL", "http://localhost:8080"), token)
+	err = u.Mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.\n",
+			user.Name, ttl, link),
+	})
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) ResetPassword(input *dto.ResetPasswordRequest) *response.ErrorResponse {
+	invalidToken := response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid or expired reset token"))
+
+	token, err := u.PasswordResetTokenRepository.GetPasswordResetTokenByHash(jwtUtil.HashToken(input.Token))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return invalidToken
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if token.UsedAt != nil || token.ExpiresAt.Before(time.Now()) {
+		return invalidToken
+	}
+
+	user, err := u.UserRepository.GetUserById(int(token.UserID))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return invalidToken
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	// checked before the token is spent, so a rejected password can be retried
+	if err := util.ValidatePassword(input.Password, user.Email); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err)
+	}
+
+	hashedPassword, err := util.HashPassword(input.Password)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	used, err := u.PasswordResetTokenRepository.UsePasswordResetToken(token, hashedPassword)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if !used {
+		return invalidToken
+	}
+
+	// sessions opened with the old password are no longer trusted
+	if err := u.RefreshTokenRepository.RevokeUserRefreshTokens(user.ID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if err := u.SessionRepository.RevokeUserSessions(user.ID); err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) issueTokens(user *model.User, sessionId uint, familyId string, mfa bool) (*dto.TokenResponse, error) {
+	accessToken, err := jwtUtil.GenerateJWT(user.ID, sessionId, user.RoleList(), mfa)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwtUtil.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.RefreshTokenRepository.CreateRefreshToken(&model.RefreshToken{
+		UserID:    user.ID,
+		SessionID: sessionId,
+		FamilyID:  familyId,
+		MFA:       mfa,
+		TokenHash: jwtUtil.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(jwtUtil.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
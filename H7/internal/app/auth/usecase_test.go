@@ -1,15 +1,21 @@
 package auth
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	usecaseTest = NewUsecase(factory.NewFactory())
+	factoryTest = factory.NewFactory()
+	usecaseTest = NewUsecase(factoryTest)
+	clientTest  = &dto.ClientInfo{IP: "127.0.0.1", UserAgent: "go-test"}
 )
 
 func TestAuthUsecaseLoginByEmailAndPasswordSuccess(t *testing.T) {
@@ -20,7 +26,7 @@ func TestAuthUsecaseLoginByEmailAndPasswordSuccess(t *testing.T) {
 		Password: "william02",
 	}
 
-	res, err := usecaseTest.LoginByEmailAndPassword(payload)
+	res, err := usecaseTest.LoginByEmailAndPassword(payload, clientTest)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -35,9 +41,9 @@ func TestAuthUsecaseLoginByEmailAndPasswordNotFound(t *testing.T) {
 		Password: "asd",
 	}
 
-	_, err := usecaseTest.LoginByEmailAndPassword(payload)
+	_, err := usecaseTest.LoginByEmailAndPassword(payload, clientTest)
 	if asserts.Error(err.ErrorMessage) {
-		asserts.Equal(err.ErrorMessage.Error(), "User not found")
+		asserts.Equal(err.ErrorMessage.Error(), "Invalid email or password")
 	}
 }
 
@@ -49,7 +55,7 @@ func TestAuthUsecaseLoginByEmailAndPasswordInvalidCredential(t *testing.T) {
 		Password: "william123",
 	}
 
-	_, err := usecaseTest.LoginByEmailAndPassword(payload)
+	_, err := usecaseTest.LoginByEmailAndPassword(payload, clientTest)
 	if asserts.Error(err.ErrorMessage) {
 		asserts.Equal(err.ErrorMessage.Error(), "Invalid email or password")
 	}
@@ -61,7 +67,7 @@ func TestAuthUsecaseRegisterUserByEmailAndPasswordSuccess(t *testing.T) {
 	payload := &dto.NewUser{
 		Name:     "ciacia",
 		Email:    "cia@gmail.com",
-		Password: "cia02",
+		Password: "cia02secret",
 	}
 
 	res, err := usecaseTest.RegisterUserByEmailAndPassword(payload)
@@ -78,10 +84,188 @@ func TestAuthUsecaseRegisterUserByEmailAndPasswordUserExists(t *testing.T) {
 	payload := &dto.NewUser{
 		Name:     "ciacia",
 		Email:    "cia@gmail.com",
-		Password: "cia02",
+		Password: "cia02secret",
 	}
 	_, err := usecaseTest.RegisterUserByEmailAndPassword(payload)
 	if asserts.Error(err.ErrorMessage) {
 		asserts.Equal(err.ErrorMessage.Error(), "Email already exists")
 	}
 }
+
+func TestAuthUsecaseRefreshTokenRotation(t *testing.T) {
+	asserts := assert.New(t)
+
+	login, err := usecaseTest.LoginByEmailAndPassword(&dto.UserCredential{
+		Email:    "william@gmail.com",
+		Password: "william02",
+	}, clientTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := usecaseTest.RefreshToken(&dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.NotEqual(login.RefreshToken, rotated.RefreshToken)
+
+	// replaying the rotated token revokes the whole family
+	_, err = usecaseTest.RefreshToken(&dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Refresh token reuse detected")
+	}
+
+	_, err = usecaseTest.RefreshToken(&dto.RefreshTokenRequest{RefreshToken: rotated.RefreshToken})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Refresh token reuse detected")
+	}
+}
+
+func TestAuthUsecaseLogoutRevokesRefreshToken(t *testing.T) {
+	asserts := assert.New(t)
+
+	login, err := usecaseTest.LoginByEmailAndPassword(&dto.UserCredential{
+		Email:    "william@gmail.com",
+		Password: "william02",
+	}, clientTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := usecaseTest.Logout(&dto.RefreshTokenRequest{RefreshToken: login.RefreshToken}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = usecaseTest.RefreshToken(&dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	asserts.NotNil(err)
+}
+
+func TestAuthUsecaseRevokeAllSessions(t *testing.T) {
+	asserts := assert.New(t)
+
+	login, err := usecaseTest.LoginByEmailAndPassword(&dto.UserCredential{
+		Email:    "william@gmail.com",
+		Password: "william02",
+	}, clientTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal := &jwtUtil.Principal{UserID: uint(login.ID)}
+	if err := usecaseTest.RevokeAllSessions(principal); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := usecaseTest.GetSessions(principal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Empty(*sessions)
+
+	_, err = usecaseTest.RefreshToken(&dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	asserts.NotNil(err)
+}
+
+func TestAuthUsecaseResetPasswordInvalidToken(t *testing.T) {
+	asserts := assert.New(t)
+
+	err := usecaseTest.ResetPassword(&dto.ResetPasswordRequest{
+		Token:    "invalid",
+		Password: "william02",
+	})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Invalid or expired reset token")
+	}
+}
+
+func TestAuthUsecaseResetPasswordWeakPasswordKeepsToken(t *testing.T) {
+	asserts := assert.New(t)
+
+	user, errs := usecaseTest.RegisterUserByEmailAndPassword(&dto.NewUser{
+		Name:     "Reset",
+		Email:    fmt.Sprintf("reset-%d@gmail.com", time.Now().UnixNano()),
+		Password: "first-password-01",
+	})
+	if errs != nil {
+		t.Fatal(errs.ErrorMessage)
+	}
+
+	rawToken, err := jwtUtil.GenerateRandomToken(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = factoryTest.PasswordResetTokenRepository.CreatePasswordResetToken(&model.PasswordResetToken{
+		UserID:    uint(user.ID),
+		TokenHash: jwtUtil.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs = usecaseTest.ResetPassword(&dto.ResetPasswordRequest{Token: rawToken, Password: "short"})
+	if asserts.NotNil(errs) {
+		asserts.Equal(400, errs.Code)
+	}
+
+	// the rejected password didn't spend the token
+	asserts.Nil(usecaseTest.ResetPassword(&dto.ResetPasswordRequest{Token: rawToken, Password: "second-password-02"}))
+
+	errs = usecaseTest.ResetPassword(&dto.ResetPasswordRequest{Token: rawToken, Password: "third-password-03"})
+	if asserts.NotNil(errs) {
+		asserts.Equal(errs.ErrorMessage.Error(), "Invalid or expired reset token")
+	}
+}
+
+func TestAuthUsecaseForgotPasswordUnknownEmail(t *testing.T) {
+	asserts := assert.New(t)
+
+	err := usecaseTest.ForgotPassword(&dto.ForgotPasswordRequest{Email: "mmiawmiaw@gmail.com"})
+	asserts.Nil(err)
+}
+
+func TestAuthUsecaseVerifyEmailInvalidToken(t *testing.T) {
+	asserts := assert.New(t)
+
+	_, err := usecaseTest.VerifyEmail(&dto.VerifyEmailRequest{Token: "invalid"})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Invalid or expired verification token")
+	}
+}
+
+func TestAuthUsecaseLoginLockout(t *testing.T) {
+	asserts := assert.New(t)
+
+	// fresh identifiers, so earlier runs can't have locked them already
+	run := time.Now().UnixNano()
+	client := &dto.ClientInfo{IP: fmt.Sprintf("10.%d.%d.%d", run>>16&0xff, run>>8&0xff, run&0xff)}
+	payload := &dto.UserCredential{
+		Email:    fmt.Sprintf("lockout-%d@gmail.com", run),
+		Password: "wrong",
+	}
+	defer func() {
+		factoryTest.LoginThrottleRepository.ResetLoginThrottle(model.AccountThrottleKey(payload.Email))
+		factoryTest.LoginThrottleRepository.ResetLoginThrottle(model.IPThrottleKey(client.IP))
+	}()
+
+	for i := 0; i < 5; i++ {
+		_, err := usecaseTest.LoginByEmailAndPassword(payload, client)
+		if asserts.Error(err.ErrorMessage) {
+			asserts.Equal(err.ErrorMessage.Error(), "Invalid email or password")
+		}
+	}
+
+	_, err := usecaseTest.LoginByEmailAndPassword(payload, client)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(429, err.Code)
+	}
+}
+
+func TestAuthUsecaseVerifyMFAInvalidToken(t *testing.T) {
+	asserts := assert.New(t)
+
+	_, err := usecaseTest.VerifyMFA(&dto.MFAVerifyRequest{MFAToken: "invalid", Code: "123456"}, clientTest)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Invalid or expired MFA token")
+	}
+}
@@ -0,0 +1,133 @@
+package author
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) CreateAuthor(c echo.Context) error {
+	var input dto.NewAuthor
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.CreateAuthor(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Create new author success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetAllAuthors(c echo.Context) error {
+	var input dto.AuthorListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAllAuthors(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get all authors success", res.Authors).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) GetAuthorById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAuthorById(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get author by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetAuthorBooks(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.PageRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAuthorBooks(id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get author books success", res.Books).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) UpdateAuthorById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewAuthor
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.UpdateAuthor(id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Update author by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) DeleteAuthorById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.DeleteAuthor(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Delete author by id success", res).SendSuccessResponse(c)
+}
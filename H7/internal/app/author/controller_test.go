@@ -0,0 +1,56 @@
+package author
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hansandika/database"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/mocks"
+	"github.com/hansandika/internal/repository"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db       = database.GetConnection()
+	echoMock = mocks.EchoMock{E: echo.New()}
+	f        = factory.Factory{
+		AuthorRepository:     repository.InitAuthorRepository(db),
+		BookRepository:       repository.InitBookRepository(db),
+		BookSearchRepository: repository.InitMemoryBookSearchRepository(db),
+		CategoryRepository:   repository.InitCategoryRepository(db),
+		TagRepository:        repository.InitTagRepository(db),
+	}
+	controllerTest = NewController(&f)
+)
+
+func TestControllerCreateAuthorInvalidPayload(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", nil)
+	c.SetPath("/api/v1/authors")
+
+	asserts := assert.New(t)
+	// testing
+	if asserts.NoError(controllerTest.CreateAuthor(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Request body can't be empty")
+	}
+}
+
+func TestControllerGetAuthorByIdNotFound(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.SetPath("/api/v1/authors/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("404")
+
+	asserts := assert.New(t)
+	// testing
+	if asserts.NoError(controllerTest.GetAuthorById(c)) {
+		asserts.Equal(404, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Author not found")
+	}
+}
@@ -0,0 +1,22 @@
+package author
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	canManage := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_BOOKS_WRITE),
+	}
+
+	e.GET("", c.GetAllAuthors)
+	e.POST("", c.CreateAuthor, canManage...)
+	e.GET("/:id", c.GetAuthorById)
+	e.GET("/:id/books", c.GetAuthorBooks)
+	e.PUT("/:id", c.UpdateAuthorById, canManage...)
+	e.DELETE("/:id", c.DeleteAuthorById, canManage...)
+}
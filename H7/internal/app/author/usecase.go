@@ -0,0 +1,246 @@
+package author
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hansandika/internal/app/book"
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CreateAuthor(input *dto.NewAuthor) (*dto.AuthorResponse, *response.ErrorResponse)
+	GetAuthorById(id int) (*dto.AuthorResponse, *response.ErrorResponse)
+	GetAllAuthors(input *dto.AuthorListRequest) (*dto.AuthorList, *response.ErrorResponse)
+	GetAuthorBooks(id int, input *dto.PageRequest) (*dto.BookList, *response.ErrorResponse)
+	UpdateAuthor(id int, input *dto.NewAuthor) (*dto.AuthorResponse, *response.ErrorResponse)
+	DeleteAuthor(id int) (*dto.AuthorResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	AuthorRepository     repository.AuthorRepositoryInterface
+	BookRepository       repository.BookRepositoryInterface
+	BookSearchRepository repository.BookSearchRepositoryInterface
+	BookUsecase          book.UsecaseInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		AuthorRepository:     f.AuthorRepository,
+		BookRepository:       f.BookRepository,
+		BookSearchRepository: f.BookSearchRepository,
+		BookUsecase:          book.NewUsecase(f),
+	}
+}
+
+func (u *usecase) CreateAuthor(input *dto.NewAuthor) (*dto.AuthorResponse, *response.ErrorResponse) {
+	var result *dto.AuthorResponse
+
+	name := strings.TrimSpace(input.Name)
+	key := model.AuthorNameKey(name)
+	if key == "" {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid author name"))
+	}
+	if input.BirthYear != nil && input.DeathYear != nil && *input.DeathYear < *input.BirthYear {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("death_year can't be before birth_year"))
+	}
+
+	_, err := u.AuthorRepository.GetAuthorByNameKey(key)
+	if err == nil {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Author already exists"))
+	}
+	if err != constant.RECORD_NOT_FOUND {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	author, err := u.AuthorRepository.CreateAuthor(&model.Author{
+		Name:      name,
+		NameKey:   key,
+		Biography: input.Biography,
+		BirthYear: input.BirthYear,
+		DeathYear: input.DeathYear,
+	})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newAuthorResponse(author), nil
+}
+
+func (u *usecase) GetAuthorById(id int) (*dto.AuthorResponse, *response.ErrorResponse) {
+	var result *dto.AuthorResponse
+
+	author, err := u.AuthorRepository.GetAuthorById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Author not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newAuthorResponse(author), nil
+}
+
+func (u *usecase) GetAllAuthors(input *dto.AuthorListRequest) (*dto.AuthorList, *response.ErrorResponse) {
+	var result *dto.AuthorList
+
+	offset, limit := input.Window()
+	authors, total, err := u.AuthorRepository.GetAllAuthors(input.Name, offset, limit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.AuthorList{
+		Authors: []*dto.AuthorResponse{},
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+	}
+	for i := range authors {
+		result.Authors = append(result.Authors, newAuthorResponse(&authors[i]))
+	}
+
+	return result, nil
+}
+
+func (u *usecase) GetAuthorBooks(id int, input *dto.PageRequest) (*dto.BookList, *response.ErrorResponse) {
+	var result *dto.BookList
+
+	author, err := u.AuthorRepository.GetAuthorById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Author not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return u.BookUsecase.GetAllBooks(&dto.BookListRequest{
+		PageRequest: *input,
+		AuthorID:    int(author.ID),
+		Sort:        "year_published",
+	})
+}
+
+func (u *usecase) UpdateAuthor(id int, input *dto.NewAuthor) (*dto.AuthorResponse, *response.ErrorResponse) {
+	var result *dto.AuthorResponse
+
+	author, err := u.AuthorRepository.GetAuthorById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Author not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	renamed := false
+	if name := strings.TrimSpace(input.Name); name != "" && name != author.Name {
+		key := model.AuthorNameKey(name)
+		if key == "" {
+			return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid author name"))
+		}
+		if key != author.NameKey {
+			_, err := u.AuthorRepository.GetAuthorByNameKey(key)
+			if err == nil {
+				return result, response.NewErrorResponse(http.StatusConflict, errors.New("Author already exists"))
+			}
+			if err != constant.RECORD_NOT_FOUND {
+				return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+			}
+		}
+		author.Name = name
+		author.NameKey = key
+		renamed = true
+	}
+
+	if input.Biography != "" {
+		author.Biography = input.Biography
+	}
+
+	if input.BirthYear != nil {
+		author.BirthYear = input.BirthYear
+	}
+
+	if input.DeathYear != nil {
+		author.DeathYear = input.DeathYear
+	}
+
+	if author.BirthYear != nil && author.DeathYear != nil && *author.DeathYear < *author.BirthYear {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("death_year can't be before birth_year"))
+	}
+
+	author, err = u.AuthorRepository.UpdateAuthor(author)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if renamed {
+		if err := u.refreshBookAuthorNames(author); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	return newAuthorResponse(author), nil
+}
+
+func (u *usecase) DeleteAuthor(id int) (*dto.AuthorResponse, *response.ErrorResponse) {
+	var result *dto.AuthorResponse
+
+	author, err := u.AuthorRepository.GetAuthorById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Author not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	count, err := u.AuthorRepository.CountAuthorBooks(author)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if count > 0 {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Author still has books"))
+	}
+
+	if err := u.AuthorRepository.DeleteAuthor(author); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newAuthorResponse(author), nil
+}
+
+// refreshBookAuthorNames rewrites the author names stored on the books of a
+// renamed author, so listings and search see the new name.
+func (u *usecase) refreshBookAuthorNames(author *model.Author) error {
+	// a negative limit lifts the limit
+	books, _, err := u.AuthorRepository.GetAuthorBooks(author, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	for i := range books {
+		books[i].Author = model.AuthorNames(books[i].Authors)
+		if _, err := u.BookRepository.UpdateBook(&books[i]); err != nil {
+			return err
+		}
+		if err := u.BookSearchRepository.IndexBook(&books[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newAuthorResponse(author *model.Author) *dto.AuthorResponse {
+	return &dto.AuthorResponse{
+		ID:        int(author.ID),
+		Name:      author.Name,
+		Biography: author.Biography,
+		BirthYear: author.BirthYear,
+		DeathYear: author.DeathYear,
+	}
+}
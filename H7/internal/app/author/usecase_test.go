@@ -0,0 +1,52 @@
+package author
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	usecaseTest = NewUsecase(factory.NewFactory())
+)
+
+func TestAuthorUsecaseCreateAuthorInvalidYears(t *testing.T) {
+	asserts := assert.New(t)
+	birth, death := 1892, 1873
+	_, err := usecaseTest.CreateAuthor(&dto.NewAuthor{Name: "J. R. R. Tolkien", BirthYear: &birth, DeathYear: &death})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "death_year can't be before birth_year")
+	}
+}
+
+func TestAuthorUsecaseDeleteAuthorNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.DeleteAuthor(404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Author not found")
+	}
+}
+
+func TestAuthorUsecaseRecreateDeletedAuthor(t *testing.T) {
+	asserts := assert.New(t)
+	name := fmt.Sprintf("Deleted Author %d", time.Now().UnixNano())
+
+	created, err := usecaseTest.CreateAuthor(&dto.NewAuthor{Name: name})
+	if err != nil {
+		t.Fatal(err.ErrorMessage)
+	}
+	if _, err := usecaseTest.DeleteAuthor(created.ID); err != nil {
+		t.Fatal(err.ErrorMessage)
+	}
+
+	// the deleted author doesn't keep the name taken
+	recreated, err := usecaseTest.CreateAuthor(&dto.NewAuthor{Name: name})
+	if asserts.Nil(err) {
+		asserts.NotEqual(created.ID, recreated.ID)
+		usecaseTest.DeleteAuthor(recreated.ID)
+	}
+}
@@ -2,22 +2,30 @@ package book
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
 	"github.com/hansandika/pkg/util/response"
 	"github.com/labstack/echo"
 )
 
 type controller struct {
-	usecase UsecaseInterface
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
 }
 
 func NewController(f *factory.Factory) *controller {
 	return &controller{
-		usecase: NewUsecase(f),
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
 	}
 }
 
@@ -39,11 +47,22 @@ func (co *controller) CreateNewBook(c echo.Context) error {
 }
 
 func (co *controller) GetAllBooks(c echo.Context) error {
-	res, err := co.usecase.GetAllBooks()
-	if err != nil {
-		return err.SendErrorResponse(c)
+	var input dto.BookListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAllBooks(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
 	}
-	return response.NewSuccessResponse(http.StatusOK, "Get all books success", res).SendSuccessResponse(c)
+	return response.NewSuccessResponse(http.StatusOK, "Get all books success", res.Books).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
 }
 
 func (co *controller) GetBookById(c echo.Context) error {
@@ -59,6 +78,14 @@ func (co *controller) GetBookById(c echo.Context) error {
 	return response.NewSuccessResponse(http.StatusOK, "Get book by id success", res).SendSuccessResponse(c)
 }
 
+func (co *controller) GetBookByISBN(c echo.Context) error {
+	res, errs := co.usecase.GetBookByISBN(c.Param("isbn"))
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get book by isbn success", res).SendSuccessResponse(c)
+}
+
 func (co *controller) UpdateBookById(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -93,3 +120,146 @@ func (co *controller) DeleteBookById(c echo.Context) error {
 	}
 	return response.NewSuccessResponse(http.StatusOK, "Delete book by id success", res).SendSuccessResponse(c)
 }
+
+func (co *controller) SearchBooks(c echo.Context) error {
+	var input dto.BookSearchRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.SearchBooks(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Search books success", res.Results).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) UploadCover(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	// leaves room for the multipart headers around the file
+	limit := int64(CoverMaxBytes())
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, limit+1<<20)
+
+	file, err := c.FormFile("cover")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return response.NewErrorResponse(http.StatusRequestEntityTooLarge, errors.New("Cover exceeds the size limit")).SendErrorResponse(c)
+		}
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Cover file is required")).SendErrorResponse(c)
+	}
+	if file.Size > limit {
+		return response.NewErrorResponse(http.StatusRequestEntityTooLarge, errors.New("Cover exceeds the size limit")).SendErrorResponse(c)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+	defer src.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(src, limit+1))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.UploadCover(id, data)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Upload book cover success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) ImportBooks(c echo.Context) error {
+	input := dto.BookImportRequest{Format: c.QueryParam("format")}
+	if dryRun := c.QueryParam("dry_run"); dryRun != "" {
+		value, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid dry_run value")).SendErrorResponse(c)
+		}
+		input.DryRun = value
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, int64(ImportMaxBytes()))
+
+	// the file is either uploaded as multipart form data or sent as the body
+	var body io.Reader = req.Body
+	name, contentType := "", req.Header.Get(echo.HeaderContentType)
+	if strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return response.NewErrorResponse(http.StatusRequestEntityTooLarge, errors.New("Import file exceeds the size limit")).SendErrorResponse(c)
+			}
+			return response.NewErrorResponse(http.StatusBadRequest, errors.New("Import file is required")).SendErrorResponse(c)
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+		}
+		defer src.Close()
+
+		body, name, contentType = src, file.Filename, file.Header.Get(echo.HeaderContentType)
+	}
+
+	if input.Format == "" {
+		input.Format = ImportFormat(name, contentType)
+	}
+	if input.Format == "" {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Unknown import format, use csv, json or ndjson")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.ImportBooks(body, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Import books success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) ExportBooks(c echo.Context) error {
+	var input dto.BookExportRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if input.Format == "" {
+		input.Format = "json"
+	}
+
+	write, errs := co.usecase.ExportBooks(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+
+	contentType, filename := exportFile(input.Format, time.Now())
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	// the status is already sent, a failure can only cut the download short
+	if err := write(c.Response()); err != nil {
+		c.Logger().Error(err)
+	}
+	return nil
+}
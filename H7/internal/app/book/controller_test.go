@@ -17,9 +17,15 @@ import (
 )
 
 var (
-	db             = database.GetConnection()
-	echoMock       = mocks.EchoMock{E: echo.New()}
-	f              = factory.Factory{BookRepository: repository.InitBookRepository(db)}
+	db       = database.GetConnection()
+	echoMock = mocks.EchoMock{E: echo.New()}
+	f        = factory.Factory{
+		BookRepository:       repository.InitBookRepository(db),
+		BookSearchRepository: repository.InitMemoryBookSearchRepository(db),
+		AuthorRepository:     repository.InitAuthorRepository(db),
+		CategoryRepository:   repository.InitCategoryRepository(db),
+		TagRepository:        repository.InitTagRepository(db),
+	}
 	controllerTest = NewController(&f)
 )
 
@@ -220,3 +226,14 @@ func TestControllerDeleteBookSuccess(t *testing.T) {
 		asserts.Contains(body, "year_published")
 	}
 }
+
+func TestControllerSearchBooksMissingQuery(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.SetPath("/api/v1/books/search")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(controllerTest.SearchBooks(c)) {
+		asserts.Equal(400, rec.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package book
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+)
+
+// coverThumbnails are the widths covers are scaled down to, keyed by name.
+var coverThumbnails = map[string]int{
+	"small":  160,
+	"medium": 480,
+}
+
+// CoverMaxBytes reads COVER_MAX_BYTES, 5 MiB by default.
+func CoverMaxBytes() int {
+	return util.GetenvInt("COVER_MAX_BYTES", 5<<20)
+}
+
+// CoverMaxDimension reads COVER_MAX_DIMENSION, the largest width or height
+// in pixels a cover may have.
+func CoverMaxDimension() int {
+	return util.GetenvInt("COVER_MAX_DIMENSION", 6000)
+}
+
+func coverKey(bookId uint, name string) string {
+	return fmt.Sprintf("covers/%d/%s", bookId, name)
+}
+
+// UploadCover replaces the book's cover. The type is sniffed from the data
+// and the image is decoded in full, so only real JPEG, PNG and WebP files are
+// stored.
+func (u *usecase) UploadCover(id int, data []byte) (*dto.BookResponse, *response.ErrorResponse) {
+	var result *dto.BookResponse
+
+	book, err := u.BookRepository.GetBookById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Book not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if len(data) > CoverMaxBytes() {
+		return result, response.NewErrorResponse(http.StatusRequestEntityTooLarge, errors.New("Cover exceeds the size limit"))
+	}
+
+	contentType, ext, ok := util.SniffImage(data)
+	if !ok {
+		return result, response.NewErrorResponse(http.StatusUnsupportedMediaType, errors.New("Cover must be a JPEG, PNG or WebP image"))
+	}
+
+	img, err := util.DecodeImage(data, CoverMaxDimension())
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Cover can't be decoded or is too large"))
+	}
+
+	thumbnails := map[string][]byte{}
+	for name, width := range coverThumbnails {
+		thumbnail, err := util.Thumbnail(img, width)
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		thumbnails[name] = thumbnail
+	}
+
+	if err := u.Storage.Put(coverKey(book.ID, "original."+ext), contentType, data); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	for name, thumbnail := range thumbnails {
+		if err := u.Storage.Put(coverKey(book.ID, name+".jpg"), "image/jpeg", thumbnail); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	previous := book.CoverType
+	if err := u.BookRepository.SetBookCover(book, ext, time.Now()); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if previous != "" && previous != ext {
+		u.Storage.Delete(coverKey(book.ID, "original."+previous))
+	}
+
+	return u.newBookResponse(book), nil
+}
+
+// removeCover deletes the book's cover files, failures are ignored.
+func (u *usecase) removeCover(bookId uint, coverType string) {
+	if coverType == "" {
+		return
+	}
+	u.Storage.Delete(coverKey(bookId, "original."+coverType))
+	for name := range coverThumbnails {
+		u.Storage.Delete(coverKey(bookId, name+".jpg"))
+	}
+}
+
+// newBookCover versions the URLs with the upload time so clients and caches
+// pick up a replaced cover.
+func (u *usecase) newBookCover(book *model.Book) *dto.BookCover {
+	version := ""
+	if book.CoverUpdatedAt != nil {
+		version = fmt.Sprintf("?v=%d", book.CoverUpdatedAt.Unix())
+	}
+	return &dto.BookCover{
+		Original: u.Storage.URL(coverKey(book.ID, "original."+book.CoverType)) + version,
+		Medium:   u.Storage.URL(coverKey(book.ID, "medium.jpg")) + version,
+		Small:    u.Storage.URL(coverKey(book.ID, "small.jpg")) + version,
+	}
+}
@@ -0,0 +1,138 @@
+package book
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/util/response"
+)
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"json":   "application/json; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+}
+
+// exportCSVHeader lists the CSV columns, lists inside a column are separated
+// by | like in imports.
+var exportCSVHeader = []string{
+	"id", "title", "description", "author", "authors", "categories", "tags",
+	"isbn_10", "isbn_13", "year_published", "average_rating", "rating_count",
+	"copies_total", "copies_available",
+}
+
+// exportFile returns the content type and a dated download name for format.
+func exportFile(format string, now time.Time) (string, string) {
+	return exportContentTypes[format], fmt.Sprintf("books-%s.%s", now.Format("20060102"), format)
+}
+
+// ExportBooks checks the request and returns the function that streams the
+// matching books, so errors are still reported before anything is written.
+func (u *usecase) ExportBooks(input *dto.BookExportRequest) (func(w io.Writer) error, *response.ErrorResponse) {
+	filter, errs := u.bookFilter(&input.BookListRequest)
+	if errs != nil {
+		return nil, errs
+	}
+
+	format := input.Format
+	if format == "" {
+		format = "json"
+	}
+
+	return func(w io.Writer) error {
+		buf := bufio.NewWriter(w)
+		var err error
+		switch format {
+		case "csv":
+			err = u.exportCSV(buf, filter)
+		case "ndjson":
+			encoder := json.NewEncoder(buf)
+			err = u.BookRepository.ExportBooks(filter, func(book *model.Book) error {
+				return encoder.Encode(u.newBookResponse(book))
+			})
+		default:
+			err = u.exportJSON(buf, filter)
+		}
+		if err != nil {
+			return err
+		}
+		return buf.Flush()
+	}, nil
+}
+
+func (u *usecase) exportJSON(w io.Writer, filter *repository.BookFilter) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	err := u.BookRepository.ExportBooks(filter, func(book *model.Book) error {
+		data, err := json.Marshal(u.newBookResponse(book))
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+func (u *usecase) exportCSV(w io.Writer, filter *repository.BookFilter) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	err := u.BookRepository.ExportBooks(filter, func(book *model.Book) error {
+		res := u.newBookResponse(book)
+
+		authors := []string{}
+		for _, author := range res.Authors {
+			authors = append(authors, author.Name)
+		}
+		categories := []string{}
+		for _, category := range res.Categories {
+			categories = append(categories, category.Path)
+		}
+
+		return writer.Write([]string{
+			strconv.Itoa(res.ID),
+			res.Title,
+			res.Description,
+			res.Author,
+			strings.Join(authors, "|"),
+			strings.Join(categories, "|"),
+			strings.Join(res.Tags, "|"),
+			res.ISBN10,
+			res.ISBN13,
+			strconv.Itoa(res.YearPublished),
+			strconv.FormatFloat(res.AverageRating, 'f', 2, 64),
+			strconv.Itoa(res.RatingCount),
+			strconv.Itoa(res.Availability.Total),
+			strconv.Itoa(res.Availability.Available),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
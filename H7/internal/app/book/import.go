@@ -0,0 +1,514 @@
+package book
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+)
+
+// ImportBatchSize reads IMPORT_BATCH_SIZE, the number of books created per
+// transaction.
+func ImportBatchSize() int {
+	return util.GetenvInt("IMPORT_BATCH_SIZE", 100)
+}
+
+// ImportMaxBytes reads IMPORT_MAX_BYTES, 32 MiB by default.
+func ImportMaxBytes() int {
+	return util.GetenvInt("IMPORT_MAX_BYTES", 32<<20)
+}
+
+// ImportFormat guesses the format of an import file from its name, then from
+// its content type. It returns an empty string when neither is known.
+func ImportFormat(name, contentType string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return "csv"
+	case "application/json":
+		return "json"
+	case "application/x-ndjson", "application/jsonl":
+		return "ndjson"
+	}
+	return ""
+}
+
+// importRow is a parsed row, errors holds what went wrong while reading it.
+type importRow struct {
+	input  dto.NewBook
+	errors []string
+}
+
+// csvColumns are the columns an import CSV may have, in any order. Lists are
+// separated by |.
+var csvColumns = map[string]bool{
+	"title":          true,
+	"description":    true,
+	"author":         true,
+	"author_ids":     true,
+	"category_ids":   true,
+	"tags":           true,
+	"isbn":           true,
+	"year_published": true,
+}
+
+func parseImport(r io.Reader, format string) ([]importRow, error) {
+	switch format {
+	case "csv":
+		return parseImportCSV(r)
+	case "json":
+		return parseImportJSON(r)
+	case "ndjson":
+		return parseImportNDJSON(r)
+	}
+	return nil, errors.New("Unknown import format")
+}
+
+// parseImportCSV expects a header row naming the columns.
+func parseImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !csvColumns[header[i]] {
+			return nil, fmt.Errorf("Unknown column %q", name)
+		}
+	}
+
+	rows := []importRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := importRow{}
+		for i, value := range record {
+			if i >= len(header) {
+				row.errors = append(row.errors, "Row has more fields than the header")
+				break
+			}
+			if err := setImportField(&row.input, header[i], strings.TrimSpace(value)); err != nil {
+				row.errors = append(row.errors, err.Error())
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func setImportField(input *dto.NewBook, column, value string) error {
+	var err error
+	switch column {
+	case "title":
+		input.Title = value
+	case "description":
+		input.Description = value
+	case "author":
+		input.Author = value
+	case "author_ids":
+		input.AuthorIDs, err = parseImportIds(column, value)
+	case "category_ids":
+		input.CategoryIDs, err = parseImportIds(column, value)
+	case "tags":
+		input.Tags = splitImportList(value)
+	case "isbn":
+		input.ISBN = value
+	case "year_published":
+		if value != "" {
+			if input.YearPublished, err = strconv.Atoi(value); err != nil {
+				err = errors.New("year_published must be a number")
+			}
+		}
+	}
+	return err
+}
+
+func splitImportList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseImportIds returns nil for an empty list, like an omitted JSON field.
+func parseImportIds(column, value string) ([]int, error) {
+	var ids []int
+	for _, item := range splitImportList(value) {
+		id, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be numbers separated by |", column)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseImportJSON reads an array of books one element at a time. Elements
+// with fields of the wrong type are reported on their row, malformed JSON
+// fails the whole file.
+func parseImportJSON(r io.Reader) ([]importRow, error) {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("Import JSON must be an array of books")
+	}
+
+	rows := []importRow{}
+	for decoder.More() {
+		row := importRow{}
+		if err := decoder.Decode(&row.input); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, err
+			}
+			row.errors = append(row.errors, importTypeError(typeErr))
+		}
+		rows = append(rows, row)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseImportNDJSON reads one book per line, blank lines are skipped. Lines
+// that aren't valid JSON only fail their own row.
+func parseImportNDJSON(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	rows := []importRow{}
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		row := importRow{}
+		if err := json.Unmarshal(line, &row.input); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				row.errors = append(row.errors, importTypeError(typeErr))
+			} else {
+				row.errors = append(row.errors, "Invalid JSON: "+err.Error())
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+func importTypeError(err *json.UnmarshalTypeError) string {
+	return fmt.Sprintf("%s can't be a %s", err.Field, err.Value)
+}
+
+// bookImporter checks rows against the catalogue and the rows before them.
+// Author and category lookups are cached since imports repeat them a lot.
+type bookImporter struct {
+	u          *usecase
+	validator  *util.CustomValidator
+	isbns      map[string]int
+	authors    map[int]bool
+	categories map[int]bool
+}
+
+// ImportBooks runs every row through the same checks as CreateNewBook and
+// creates the valid ones in batches of ImportBatchSize, each batch in its own
+// transaction. Rows with an ISBN already in the catalogue or earlier in the
+// file are duplicates.
+func (u *usecase) ImportBooks(r io.Reader, input *dto.BookImportRequest) (*dto.BookImportReport, *response.ErrorResponse) {
+	var result *dto.BookImportReport
+
+	rows, err := parseImport(r, input.Format)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return result, response.NewErrorResponse(http.StatusRequestEntityTooLarge, errors.New("Import file exceeds the size limit"))
+		}
+		return result, response.NewErrorResponse(http.StatusBadRequest, err)
+	}
+	if len(rows) == 0 {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Import file has no rows"))
+	}
+
+	importer := &bookImporter{
+		u:          u,
+		validator:  util.NewValidator(),
+		isbns:      map[string]int{},
+		authors:    map[int]bool{},
+		categories: map[int]bool{},
+	}
+
+	result = &dto.BookImportReport{
+		DryRun: input.DryRun,
+		Total:  len(rows),
+		Rows:   make([]dto.BookImportRow, len(rows)),
+	}
+	pending := []int{}
+	for i := range rows {
+		result.Rows[i] = importer.check(i+1, &rows[i])
+		if result.Rows[i].Status == constant.IMPORT_STATUS_VALID {
+			pending = append(pending, i)
+		}
+	}
+
+	if !input.DryRun {
+		batchSize := ImportBatchSize()
+		if batchSize < 1 {
+			batchSize = 1
+		}
+		for start := 0; start < len(pending); start += batchSize {
+			end := start + batchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			u.importBatch(rows, pending[start:end], result)
+		}
+	}
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case constant.IMPORT_STATUS_CREATED:
+			result.Created++
+		case constant.IMPORT_STATUS_VALID:
+			result.Valid++
+		case constant.IMPORT_STATUS_INVALID:
+			result.Invalid++
+		case constant.IMPORT_STATUS_DUPLICATE:
+			result.Duplicates++
+		case constant.IMPORT_STATUS_FAILED:
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// check validates a row without writing anything.
+func (b *bookImporter) check(number int, row *importRow) dto.BookImportRow {
+	report := dto.BookImportRow{Row: number, Errors: row.errors}
+	input := &row.input
+
+	if err := b.validator.Validator.Struct(input); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		for _, fieldErr := range fieldErrs {
+			report.Errors = append(report.Errors, fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", fieldErr.Field(), fieldErr.Tag()))
+		}
+	}
+
+	// an empty author_ids list gets past required_without
+	if len(report.Errors) == 0 && len(input.AuthorIDs) == 0 && model.AuthorNameKey(strings.TrimSpace(input.Author)) == "" {
+		report.Errors = append(report.Errors, "Invalid author name")
+	}
+	for _, name := range input.Tags {
+		if model.NormalizeTagName(name) == "" {
+			report.Errors = append(report.Errors, "Tag can't be empty")
+			break
+		}
+	}
+
+	if len(report.Errors) == 0 {
+		if err := b.checkReferences(input); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	if len(report.Errors) > 0 {
+		report.Status = constant.IMPORT_STATUS_INVALID
+		return report
+	}
+
+	if input.ISBN != "" {
+		_, isbn13, _ := util.ParseISBN(input.ISBN)
+		if earlier, ok := b.isbns[isbn13]; ok {
+			report.Status = constant.IMPORT_STATUS_DUPLICATE
+			report.Errors = []string{fmt.Sprintf("Same ISBN as row %d", earlier)}
+			return report
+		}
+		b.isbns[isbn13] = number
+
+		existing, err := b.u.BookRepository.GetBookByISBN(isbn13)
+		if err == nil {
+			report.Status = constant.IMPORT_STATUS_DUPLICATE
+			report.BookID = int(existing.ID)
+			report.Errors = []string{constant.ISBN_EXISTS.Error()}
+			return report
+		}
+		if err != constant.RECORD_NOT_FOUND {
+			report.Status = constant.IMPORT_STATUS_FAILED
+			report.Errors = []string{err.Error()}
+			return report
+		}
+	}
+
+	report.Status = constant.IMPORT_STATUS_VALID
+	return report
+}
+
+// checkReferences fails when an author or category id doesn't exist.
+func (b *bookImporter) checkReferences(input *dto.NewBook) error {
+	missing := []int{}
+	for _, id := range input.AuthorIDs {
+		if _, ok := b.authors[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		found, err := b.u.AuthorRepository.GetAuthorsByIds(missing)
+		if err != nil {
+			return err
+		}
+		for _, id := range missing {
+			b.authors[id] = false
+		}
+		for _, author := range found {
+			b.authors[int(author.ID)] = true
+		}
+	}
+	for _, id := range input.AuthorIDs {
+		if !b.authors[id] {
+			return errors.New("Author not found")
+		}
+	}
+
+	missing = []int{}
+	for _, id := range input.CategoryIDs {
+		if _, ok := b.categories[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		found, err := b.u.CategoryRepository.GetCategoriesByIds(missing)
+		if err != nil {
+			return err
+		}
+		for _, id := range missing {
+			b.categories[id] = false
+		}
+		for _, category := range found {
+			b.categories[int(category.ID)] = true
+		}
+	}
+	for _, id := range input.CategoryIDs {
+		if !b.categories[id] {
+			return errors.New("Category not found")
+		}
+	}
+	return nil
+}
+
+// importBatch creates the rows at indexes together, along with the new
+// authors and tags they name. A failed batch marks all of its rows as failed
+// and leaves none of them behind.
+func (u *usecase) importBatch(rows []importRow, indexes []int, report *dto.BookImportReport) {
+	books := []*model.Book{}
+	created := []int{}
+	for _, i := range indexes {
+		book, errs := u.newImportBook(&rows[i].input)
+		if errs != nil {
+			report.Rows[i].Status = constant.IMPORT_STATUS_FAILED
+			report.Rows[i].Errors = []string{errs.ErrorMessage.Error()}
+			continue
+		}
+		books = append(books, book)
+		created = append(created, i)
+	}
+	if len(books) == 0 {
+		return
+	}
+
+	if err := u.BookRepository.CreateBooks(books); err != nil {
+		for _, i := range created {
+			report.Rows[i].Status = constant.IMPORT_STATUS_FAILED
+			report.Rows[i].Errors = []string{err.Error()}
+		}
+		return
+	}
+
+	for n, i := range created {
+		report.Rows[i].Status = constant.IMPORT_STATUS_CREATED
+		report.Rows[i].BookID = int(books[n].ID)
+		if err := u.BookSearchRepository.IndexBook(books[n]); err != nil {
+			report.Rows[i].Errors = []string{"Book created but not indexed for search: " + err.Error()}
+		}
+	}
+}
+
+func (u *usecase) newImportBook(input *dto.NewBook) (*model.Book, *response.ErrorResponse) {
+	authors, errs := u.resolveAuthors(input)
+	if errs != nil {
+		return nil, errs
+	}
+
+	categories, errs := u.resolveCategories(input.CategoryIDs)
+	if errs != nil {
+		return nil, errs
+	}
+
+	tags, errs := u.resolveTags(input.Tags)
+	if errs != nil {
+		return nil, errs
+	}
+
+	book := &model.Book{
+		Title:         input.Title,
+		Description:   input.Description,
+		Author:        model.AuthorNames(authors),
+		Authors:       authors,
+		Categories:    categories,
+		Tags:          tags,
+		YearPublished: input.YearPublished,
+	}
+	if input.ISBN != "" {
+		isbn10, isbn13, _ := util.ParseISBN(input.ISBN)
+		book.ISBN13 = &isbn13
+		if isbn10 != "" {
+			book.ISBN10 = &isbn10
+		}
+	}
+	return book, nil
+}
@@ -1,13 +1,31 @@
 package book
 
 import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
 	"github.com/labstack/echo"
 )
 
 func (c *controller) Route(e *echo.Group) {
+	canManage := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_BOOKS_WRITE),
+	}
+	canExport := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_BOOKS_READ),
+	}
+
 	e.GET("", c.GetAllBooks)
-	e.POST("", c.CreateNewBook)
+	e.POST("", c.CreateNewBook, canManage...)
+	e.POST("/import", c.ImportBooks, canManage...)
+	e.GET("/export", c.ExportBooks, canExport...)
+	e.GET("/search", c.SearchBooks)
+	e.GET("/isbn/:isbn", c.GetBookByISBN)
 	e.GET("/:id", c.GetBookById)
-	e.PUT("/:id", c.UpdateBookById)
-	e.DELETE("/:id", c.DeleteBookById)
+	e.PUT("/:id", c.UpdateBookById, canManage...)
+	e.DELETE("/:id", c.DeleteBookById, canManage...)
+	e.PUT("/:id/cover", c.UploadCover, canManage...)
 }
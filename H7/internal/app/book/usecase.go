@@ -2,56 +2,101 @@ package book
 
 import (
 	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strings"
 
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
 	"github.com/hansandika/internal/model"
 	"github.com/hansandika/internal/repository"
 	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/storage"
+	"github.com/hansandika/pkg/util"
 	"github.com/hansandika/pkg/util/response"
 )
 
+// snippetWidth is the length of the excerpts returned with search results.
+const snippetWidth = 160
+
 type UsecaseInterface interface {
 	CreateNewBook(input *dto.NewBook) (*dto.BookResponse, *response.ErrorResponse)
 	GetBookById(id int) (*dto.BookResponse, *response.ErrorResponse)
-	GetAllBooks() ([]*dto.BookResponse, *response.ErrorResponse)
+	GetBookByISBN(isbn string) (*dto.BookResponse, *response.ErrorResponse)
+	GetAllBooks(input *dto.BookListRequest) (*dto.BookList, *response.ErrorResponse)
 	UpdateBook(id int, input *dto.NewBook) (*dto.BookResponse, *response.ErrorResponse)
 	DeleteBook(id int) (*dto.BookResponse, *response.ErrorResponse)
+	SearchBooks(input *dto.BookSearchRequest) (*dto.BookSearchList, *response.ErrorResponse)
+	UploadCover(id int, data []byte) (*dto.BookResponse, *response.ErrorResponse)
+	ImportBooks(r io.Reader, input *dto.BookImportRequest) (*dto.BookImportReport, *response.ErrorResponse)
+	ExportBooks(input *dto.BookExportRequest) (func(w io.Writer) error, *response.ErrorResponse)
 }
 
 type usecase struct {
-	BookRepository repository.BookRepositoryInterface
+	BookRepository       repository.BookRepositoryInterface
+	BookSearchRepository repository.BookSearchRepositoryInterface
+	AuthorRepository     repository.AuthorRepositoryInterface
+	CategoryRepository   repository.CategoryRepositoryInterface
+	Storage              storage.Storage
 }
 
 func NewUsecase(f *factory.Factory) UsecaseInterface {
 	return &usecase{
-		BookRepository: f.BookRepository,
+		BookRepository:       f.BookRepository,
+		BookSearchRepository: f.BookSearchRepository,
+		AuthorRepository:     f.AuthorRepository,
+		CategoryRepository:   f.CategoryRepository,
+		Storage:              f.Storage,
 	}
 }
 
 func (u *usecase) CreateNewBook(input *dto.NewBook) (*dto.BookResponse, *response.ErrorResponse) {
 	var result *dto.BookResponse
 
+	authors, errs := u.resolveAuthors(input)
+	if errs != nil {
+		return result, errs
+	}
+
+	categories, errs := u.resolveCategories(input.CategoryIDs)
+	if errs != nil {
+		return result, errs
+	}
+
+	tags, errs := u.resolveTags(input.Tags)
+	if errs != nil {
+		return result, errs
+	}
+
+	isbn10, isbn13, errs := u.resolveISBN(input.ISBN, 0)
+	if errs != nil {
+		return result, errs
+	}
+
 	book, err := u.BookRepository.CreateNewBook(&model.Book{
 		Title:         input.Title,
 		Description:   input.Description,
-		Author:        input.Author,
+		Author:        model.AuthorNames(authors),
+		ISBN10:        isbn10,
+		ISBN13:        isbn13,
 		YearPublished: input.YearPublished,
+		Authors:       authors,
+		Categories:    categories,
+		Tags:          tags,
 	})
 	if err != nil {
+		if err == constant.ISBN_EXISTS {
+			return result, response.NewErrorResponse(http.StatusConflict, err)
+		}
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	result = &dto.BookResponse{
-		ID:            int(book.ID),
-		Title:         book.Title,
-		Description:   book.Description,
-		Author:        book.Author,
-		YearPublished: book.YearPublished,
+	if err := u.BookSearchRepository.IndexBook(book); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	return result, nil
+	return u.newBookResponse(book), nil
 }
 
 func (u *usecase) GetBookById(id int) (*dto.BookResponse, *response.ErrorResponse) {
@@ -65,38 +110,86 @@ func (u *usecase) GetBookById(id int) (*dto.BookResponse, *response.ErrorRespons
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	result = &dto.BookResponse{
-		ID:            int(book.ID),
-		Title:         book.Title,
-		Description:   book.Description,
-		Author:        book.Author,
-		YearPublished: book.YearPublished,
+	return u.newBookResponse(book), nil
+}
+
+func (u *usecase) GetBookByISBN(isbn string) (*dto.BookResponse, *response.ErrorResponse) {
+	var result *dto.BookResponse
+
+	_, isbn13, ok := util.ParseISBN(isbn)
+	if !ok {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid ISBN"))
 	}
 
-	return result, nil
+	book, err := u.BookRepository.GetBookByISBN(isbn13)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Book not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return u.newBookResponse(book), nil
 }
 
-func (u *usecase) GetAllBooks() ([]*dto.BookResponse, *response.ErrorResponse) {
-	var result []*dto.BookResponse
+func (u *usecase) GetAllBooks(input *dto.BookListRequest) (*dto.BookList, *response.ErrorResponse) {
+	var result *dto.BookList
+
+	filter, errs := u.bookFilter(input)
+	if errs != nil {
+		return result, errs
+	}
 
-	books, err := u.BookRepository.GetAllBooks()
+	offset, limit := input.Window()
+	filter.Offset, filter.Limit = offset, limit
+	books, total, err := u.BookRepository.GetAllBooks(filter)
 	if err != nil {
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	for _, book := range books {
-		result = append(result, &dto.BookResponse{
-			ID:            int(book.ID),
-			Title:         book.Title,
-			Description:   book.Description,
-			Author:        book.Author,
-			YearPublished: book.YearPublished,
-		})
+	result = &dto.BookList{
+		Books:  []*dto.BookResponse{},
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+	for i := range books {
+		result.Books = append(result.Books, u.newBookResponse(&books[i]))
 	}
 
 	return result, nil
 }
 
+// bookFilter converts the listing parameters, paging aside.
+func (u *usecase) bookFilter(input *dto.BookListRequest) (*repository.BookFilter, *response.ErrorResponse) {
+	if input.YearFrom != 0 && input.YearTo != 0 && input.YearFrom > input.YearTo {
+		return nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("year_from can't be after year_to"))
+	}
+
+	filter := &repository.BookFilter{
+		Author:        input.Author,
+		AuthorID:      input.AuthorID,
+		Tag:           model.NormalizeTagName(input.Tag),
+		TitleContains: input.Title,
+		YearFrom:      input.YearFrom,
+		YearTo:        input.YearTo,
+		Sort:          input.Sort,
+		Desc:          input.Order == "desc",
+	}
+
+	if input.CategoryID != 0 {
+		category, err := u.CategoryRepository.GetCategoryById(input.CategoryID)
+		if err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return nil, response.NewErrorResponse(http.StatusNotFound, errors.New("Category not found"))
+			}
+			return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		filter.CategoryPath = category.Path
+	}
+	return filter, nil
+}
+
 func (u *usecase) UpdateBook(id int, input *dto.NewBook) (*dto.BookResponse, *response.ErrorResponse) {
 	var result *dto.BookResponse
 
@@ -116,8 +209,37 @@ func (u *usecase) UpdateBook(id int, input *dto.NewBook) (*dto.BookResponse, *re
 		book.Description = input.Description
 	}
 
-	if input.Author != "" {
-		book.Author = input.Author
+	if input.ISBN != "" {
+		isbn10, isbn13, errs := u.resolveISBN(input.ISBN, book.ID)
+		if errs != nil {
+			return result, errs
+		}
+		book.ISBN10, book.ISBN13 = isbn10, isbn13
+	}
+
+	if input.Author != "" || len(input.AuthorIDs) > 0 {
+		authors, errs := u.resolveAuthors(input)
+		if errs != nil {
+			return result, errs
+		}
+		book.Authors = authors
+		book.Author = model.AuthorNames(authors)
+	}
+
+	if input.CategoryIDs != nil {
+		categories, errs := u.resolveCategories(input.CategoryIDs)
+		if errs != nil {
+			return result, errs
+		}
+		book.Categories = categories
+	}
+
+	if input.Tags != nil {
+		tags, errs := u.resolveTags(input.Tags)
+		if errs != nil {
+			return result, errs
+		}
+		book.Tags = tags
 	}
 
 	if input.YearPublished != 0 {
@@ -126,18 +248,17 @@ func (u *usecase) UpdateBook(id int, input *dto.NewBook) (*dto.BookResponse, *re
 
 	book, err = u.BookRepository.UpdateBook(book)
 	if err != nil {
+		if err == constant.ISBN_EXISTS {
+			return result, response.NewErrorResponse(http.StatusConflict, err)
+		}
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	result = &dto.BookResponse{
-		ID:            int(book.ID),
-		Title:         book.Title,
-		Description:   book.Description,
-		Author:        book.Author,
-		YearPublished: book.YearPublished,
+	if err := u.BookSearchRepository.IndexBook(book); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	return result, nil
+	return u.newBookResponse(book), nil
 }
 
 func (u *usecase) DeleteBook(id int) (*dto.BookResponse, *response.ErrorResponse) {
@@ -151,18 +272,252 @@ func (u *usecase) DeleteBook(id int) (*dto.BookResponse, *response.ErrorResponse
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
+	if availability := newBookAvailability(book.Copies); availability.OnLoan > 0 || availability.OnHold > 0 {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Book has copies on loan or on hold"))
+	}
+
 	err = u.BookRepository.DeleteBook(book)
 	if err != nil {
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	result = &dto.BookResponse{
+	if err := u.BookSearchRepository.RemoveBook(book.ID); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	// the book is already gone, files left behind only take up space
+	u.removeCover(book.ID, book.CoverType)
+
+	return u.newBookResponse(book), nil
+}
+
+func (u *usecase) SearchBooks(input *dto.BookSearchRequest) (*dto.BookSearchList, *response.ErrorResponse) {
+	var result *dto.BookSearchList
+
+	terms := util.Tokenize(input.Query)
+	if len(terms) == 0 {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Search query has no words"))
+	}
+
+	offset, limit := input.Window()
+	hits, total, err := u.BookSearchRepository.SearchBooks(input.Query, offset, limit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.BookSearchList{
+		Results: []*dto.BookSearchResponse{},
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+	}
+	for i := range hits {
+		highlights := map[string]string{}
+		fields := map[string]string{
+			"title":       hits[i].Title,
+			"description": hits[i].Description,
+			"author":      hits[i].Author,
+		}
+		for field, text := range fields {
+			if snippet := util.Highlight(text, terms, snippetWidth); snippet != "" {
+				highlights[field] = snippet
+			}
+		}
+
+		result.Results = append(result.Results, &dto.BookSearchResponse{
+			BookResponse: *u.newBookResponse(&hits[i].Book),
+			Score:        hits[i].Score,
+			Highlights:   highlights,
+		})
+	}
+
+	return result, nil
+}
+
+// resolveAuthors loads the authors listed in AuthorIDs, keeping their order.
+// Without ids the Author name is matched to an existing author, or an unsaved
+// one is returned that the book repository creates along with the book.
+func (u *usecase) resolveAuthors(input *dto.NewBook) ([]model.Author, *response.ErrorResponse) {
+	if len(input.AuthorIDs) == 0 {
+		name := strings.TrimSpace(input.Author)
+		key := model.AuthorNameKey(name)
+		if key == "" {
+			return nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid author name"))
+		}
+
+		author, err := u.AuthorRepository.GetAuthorByNameKey(key)
+		if err == constant.RECORD_NOT_FOUND {
+			return []model.Author{{Name: name, NameKey: key}}, nil
+		}
+		if err != nil {
+			return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		return []model.Author{*author}, nil
+	}
+
+	found, err := u.AuthorRepository.GetAuthorsByIds(input.AuthorIDs)
+	if err != nil {
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	byId := map[int]model.Author{}
+	for _, author := range found {
+		byId[int(author.ID)] = author
+	}
+
+	authors := []model.Author{}
+	seen := map[int]bool{}
+	for _, id := range input.AuthorIDs {
+		author, ok := byId[id]
+		if !ok {
+			return nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("Author not found"))
+		}
+		if !seen[id] {
+			seen[id] = true
+			authors = append(authors, author)
+		}
+	}
+	return authors, nil
+}
+
+func (u *usecase) resolveCategories(ids []int) ([]model.Category, *response.ErrorResponse) {
+	categories := []model.Category{}
+	if len(ids) == 0 {
+		return categories, nil
+	}
+
+	found, err := u.CategoryRepository.GetCategoriesByIds(ids)
+	if err != nil {
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	byId := map[int]model.Category{}
+	for _, category := range found {
+		byId[int(category.ID)] = category
+	}
+
+	seen := map[int]bool{}
+	for _, id := range ids {
+		category, ok := byId[id]
+		if !ok {
+			return nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("Category not found"))
+		}
+		if !seen[id] {
+			seen[id] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+// resolveTags returns an unsaved tag per distinct name, tags are free-form. The
+// book repository finds or creates them along with the book.
+func (u *usecase) resolveTags(names []string) ([]model.Tag, *response.ErrorResponse) {
+	tags := []model.Tag{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = model.NormalizeTagName(name)
+		if name == "" {
+			return nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("Tag can't be empty"))
+		}
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, model.Tag{Name: name})
+		}
+	}
+	return tags, nil
+}
+
+// resolveISBN returns both forms of isbn, nil when isbn is empty. It fails
+// when another book than bookId already has the ISBN.
+func (u *usecase) resolveISBN(isbn string, bookId uint) (*string, *string, *response.ErrorResponse) {
+	if isbn == "" {
+		return nil, nil, nil
+	}
+
+	isbn10, isbn13, ok := util.ParseISBN(isbn)
+	if !ok {
+		return nil, nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid ISBN"))
+	}
+
+	existing, err := u.BookRepository.GetBookByISBN(isbn13)
+	if err == nil && existing.ID != bookId {
+		return nil, nil, response.NewErrorResponse(http.StatusConflict, constant.ISBN_EXISTS)
+	}
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return nil, nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if isbn10 == "" {
+		return nil, &isbn13, nil
+	}
+	return &isbn10, &isbn13, nil
+}
+
+func (u *usecase) newBookResponse(book *model.Book) *dto.BookResponse {
+	authors := []dto.AuthorSummary{}
+	for _, author := range book.Authors {
+		authors = append(authors, dto.AuthorSummary{
+			ID:   int(author.ID),
+			Name: author.Name,
+		})
+	}
+
+	categories := []dto.CategorySummary{}
+	for _, category := range book.Categories {
+		categories = append(categories, dto.CategorySummary{
+			ID:   int(category.ID),
+			Name: category.Name,
+			Path: category.FullName,
+		})
+	}
+
+	result := &dto.BookResponse{
 		ID:            int(book.ID),
 		Title:         book.Title,
 		Description:   book.Description,
 		Author:        book.Author,
+		Authors:       authors,
+		Categories:    categories,
+		Tags:          model.TagNames(book.Tags),
 		YearPublished: book.YearPublished,
+		Availability:  newBookAvailability(book.Copies),
+		AverageRating: averageRating(book),
+		RatingCount:   book.RatingCount,
+	}
+	if book.ISBN10 != nil {
+		result.ISBN10 = *book.ISBN10
 	}
+	if book.ISBN13 != nil {
+		result.ISBN13 = *book.ISBN13
+	}
+	if book.CoverType != "" {
+		result.Cover = u.newBookCover(book)
+	}
+	return result
+}
 
-	return result, nil
+func newBookAvailability(copies []model.BookCopy) dto.BookAvailability {
+	availability := dto.BookAvailability{Total: len(copies)}
+	for _, bookCopy := range copies {
+		switch bookCopy.Status {
+		case constant.COPY_STATUS_AVAILABLE:
+			availability.Available++
+		case constant.COPY_STATUS_ON_LOAN:
+			availability.OnLoan++
+		case constant.COPY_STATUS_ON_HOLD:
+			availability.OnHold++
+		case constant.COPY_STATUS_LOST:
+			availability.Lost++
+		case constant.COPY_STATUS_IN_REPAIR:
+			availability.InRepair++
+		}
+	}
+	return availability
+}
+
+// averageRating is rounded to two decimals, 0 for books without reviews.
+func averageRating(book *model.Book) float64 {
+	if book.RatingCount == 0 {
+		return 0
+	}
+	return math.Round(float64(book.RatingSum)/float64(book.RatingCount)*100) / 100
 }
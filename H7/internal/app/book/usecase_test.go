@@ -1,10 +1,14 @@
 package book
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,16 +18,24 @@ var (
 
 func TestBookUsecaseGetAllBooks(t *testing.T) {
 	asserts := assert.New(t)
-	res, err := usecaseTest.GetAllBooks()
+	res, err := usecaseTest.GetAllBooks(&dto.BookListRequest{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	for _, val := range res {
+	for _, val := range res.Books {
 		asserts.NotEmpty(val.ID)
 	}
 }
 
+func TestBookUsecaseGetAllBooksInvalidYearRange(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.GetAllBooks(&dto.BookListRequest{YearFrom: 2000, YearTo: 1990})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "year_from can't be after year_to")
+	}
+}
+
 func TestBookUsecaseGetBookByIdSuccess(t *testing.T) {
 	asserts := assert.New(t)
 	res, err := usecaseTest.GetBookById(4)
@@ -56,6 +68,29 @@ func TestBookUsecaseCreateBookSuccess(t *testing.T) {
 	asserts.NotEmpty(res.ID)
 }
 
+func TestBookUsecaseCreateBookConflictLeavesNoAuthor(t *testing.T) {
+	asserts := assert.New(t)
+	payload := &dto.NewBook{
+		Title:         "Elementary Number Theory",
+		Description:   "An introduction to number theory.",
+		Author:        "Isbn Conflict Author",
+		ISBN:          "978-0-306-40615-7",
+		YearPublished: 1974,
+	}
+	if _, err := usecaseTest.CreateNewBook(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	payload.Author = "Isbn Conflict Second Author"
+	_, err := usecaseTest.CreateNewBook(payload)
+	if asserts.NotNil(err) {
+		asserts.Equal(http.StatusConflict, err.Code)
+	}
+
+	_, lookupErr := usecaseTest.(*usecase).AuthorRepository.GetAuthorByNameKey(model.AuthorNameKey(payload.Author))
+	asserts.Equal(constant.RECORD_NOT_FOUND, lookupErr)
+}
+
 func TestBookUsecaseUpdateBookByIdNotFound(t *testing.T) {
 	asserts := assert.New(t)
 	payload := &dto.NewBook{
@@ -101,3 +136,50 @@ func TestBookUsecaseDeleteBookByIdSuccess(t *testing.T) {
 	}
 	asserts.NotEmpty(res.ID)
 }
+
+func TestBookUsecaseGetBookByISBNInvalid(t *testing.T) {
+	asserts := assert.New(t)
+
+	_, err := usecaseTest.GetBookByISBN("978-0-261-10320-8")
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Invalid ISBN")
+	}
+}
+
+func TestBookUsecaseUploadCoverUnsupportedType(t *testing.T) {
+	asserts := assert.New(t)
+
+	_, err := usecaseTest.UploadCover(4, []byte("GIF89a\x01\x00\x01\x00"))
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Cover must be a JPEG, PNG or WebP image")
+	}
+}
+
+func TestBookUsecaseImportBooksDryRun(t *testing.T) {
+	asserts := assert.New(t)
+
+	csv := "title,description,author,isbn,year_published\n" +
+		"The Hobbit,A hobbit's journey,J. R. R. Tolkien,0-261-10320-2,1937\n" +
+		",No title,Nobody,,2001\n" +
+		"The Hobbit,Same edition,J. R. R. Tolkien,978-0-261-10320-7,1937\n"
+	res, err := usecaseTest.ImportBooks(strings.NewReader(csv), &dto.BookImportRequest{Format: "csv", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(3, res.Total)
+	asserts.Equal(0, res.Created)
+	asserts.Equal(1, res.Invalid)
+	asserts.Equal(constant.IMPORT_STATUS_INVALID, res.Rows[1].Status)
+	asserts.Equal(constant.IMPORT_STATUS_DUPLICATE, res.Rows[2].Status)
+}
+
+func TestBookUsecaseExportBooksInvalidYearRange(t *testing.T) {
+	asserts := assert.New(t)
+
+	input := &dto.BookExportRequest{Format: "csv"}
+	input.YearFrom, input.YearTo = 2000, 1990
+	_, err := usecaseTest.ExportBooks(input)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "year_from can't be after year_to")
+	}
+}
@@ -0,0 +1,128 @@
+package bookcopy
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) CreateBookCopy(c echo.Context) error {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewBookCopy
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.CreateBookCopy(bookId, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Create new copy success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetBookCopies(c echo.Context) error {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.BookCopyListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetBookCopies(bookId, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get all copies success", res.Copies).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) GetBookCopyById(c echo.Context) error {
+	bookId, id, err := parseIds(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetBookCopyById(bookId, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get copy by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) UpdateBookCopyById(c echo.Context) error {
+	bookId, id, err := parseIds(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewBookCopy
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.UpdateBookCopy(bookId, id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Update copy by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) DeleteBookCopyById(c echo.Context) error {
+	bookId, id, err := parseIds(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.DeleteBookCopy(bookId, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Delete copy by id success", res).SendSuccessResponse(c)
+}
+
+func parseIds(c echo.Context) (int, int, error) {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.Atoi(c.Param("copyId"))
+	return bookId, id, err
+}
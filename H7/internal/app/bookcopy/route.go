@@ -0,0 +1,21 @@
+package bookcopy
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	canManage := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_BOOKS_WRITE),
+	}
+
+	e.GET("", c.GetBookCopies)
+	e.POST("", c.CreateBookCopy, canManage...)
+	e.GET("/:copyId", c.GetBookCopyById)
+	e.PUT("/:copyId", c.UpdateBookCopyById, canManage...)
+	e.DELETE("/:copyId", c.DeleteBookCopyById, canManage...)
+}
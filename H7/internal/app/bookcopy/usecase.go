@@ -0,0 +1,251 @@
+package bookcopy
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hansandika/internal/app/hold"
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CreateBookCopy(bookId int, input *dto.NewBookCopy) (*dto.BookCopyResponse, *response.ErrorResponse)
+	GetBookCopies(bookId int, input *dto.BookCopyListRequest) (*dto.BookCopyList, *response.ErrorResponse)
+	GetBookCopyById(bookId, id int) (*dto.BookCopyResponse, *response.ErrorResponse)
+	UpdateBookCopy(bookId, id int, input *dto.NewBookCopy) (*dto.BookCopyResponse, *response.ErrorResponse)
+	DeleteBookCopy(bookId, id int) (*dto.BookCopyResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	BookRepository     repository.BookRepositoryInterface
+	BookCopyRepository repository.BookCopyRepositoryInterface
+	HoldRepository     repository.HoldRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		BookRepository:     f.BookRepository,
+		BookCopyRepository: f.BookCopyRepository,
+		HoldRepository:     f.HoldRepository,
+	}
+}
+
+func (u *usecase) CreateBookCopy(bookId int, input *dto.NewBookCopy) (*dto.BookCopyResponse, *response.ErrorResponse) {
+	var result *dto.BookCopyResponse
+
+	if errs := u.checkBook(bookId); errs != nil {
+		return result, errs
+	}
+
+	barcode := strings.TrimSpace(input.Barcode)
+	if errs := u.checkBarcode(barcode, 0); errs != nil {
+		return result, errs
+	}
+
+	bookCopy := &model.BookCopy{
+		BookID:        uint(bookId),
+		Barcode:       barcode,
+		AcquiredAt:    input.AcquiredAt,
+		ShelfLocation: strings.TrimSpace(input.ShelfLocation),
+		Condition:     "good",
+		Status:        constant.COPY_STATUS_AVAILABLE,
+	}
+	if input.Condition != "" {
+		bookCopy.Condition = input.Condition
+	}
+	if input.Status != "" {
+		bookCopy.Status = input.Status
+	}
+
+	bookCopy, err := u.BookCopyRepository.CreateBookCopy(bookCopy)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if bookCopy.Status == constant.COPY_STATUS_AVAILABLE {
+		return u.releaseCopy(bookCopy)
+	}
+	return newBookCopyResponse(bookCopy), nil
+}
+
+func (u *usecase) GetBookCopies(bookId int, input *dto.BookCopyListRequest) (*dto.BookCopyList, *response.ErrorResponse) {
+	var result *dto.BookCopyList
+
+	if errs := u.checkBook(bookId); errs != nil {
+		return result, errs
+	}
+
+	offset, limit := input.Window()
+	copies, total, err := u.BookCopyRepository.GetBookCopies(bookId, input.Status, offset, limit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.BookCopyList{
+		Copies: []*dto.BookCopyResponse{},
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+	for i := range copies {
+		result.Copies = append(result.Copies, newBookCopyResponse(&copies[i]))
+	}
+
+	return result, nil
+}
+
+func (u *usecase) GetBookCopyById(bookId, id int) (*dto.BookCopyResponse, *response.ErrorResponse) {
+	var result *dto.BookCopyResponse
+
+	bookCopy, errs := u.getBookCopy(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	return newBookCopyResponse(bookCopy), nil
+}
+
+func (u *usecase) UpdateBookCopy(bookId, id int, input *dto.NewBookCopy) (*dto.BookCopyResponse, *response.ErrorResponse) {
+	var result *dto.BookCopyResponse
+
+	bookCopy, errs := u.getBookCopy(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	barcode := strings.TrimSpace(input.Barcode)
+	if barcode != bookCopy.Barcode {
+		if errs := u.checkBarcode(barcode, bookCopy.ID); errs != nil {
+			return result, errs
+		}
+		bookCopy.Barcode = barcode
+	}
+
+	if input.AcquiredAt != nil {
+		bookCopy.AcquiredAt = input.AcquiredAt
+	}
+
+	if input.ShelfLocation != "" {
+		bookCopy.ShelfLocation = strings.TrimSpace(input.ShelfLocation)
+	}
+
+	if input.Condition != "" {
+		bookCopy.Condition = input.Condition
+	}
+
+	if input.Status != "" && input.Status != bookCopy.Status {
+		if err := u.BookCopyRepository.SetBookCopyStatus(bookCopy, input.Status); err != nil {
+			if err == constant.COPY_IN_USE {
+				return result, response.NewErrorResponse(http.StatusConflict, err)
+			}
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	bookCopy, err := u.BookCopyRepository.UpdateBookCopy(bookCopy)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if input.Status == constant.COPY_STATUS_AVAILABLE {
+		return u.releaseCopy(bookCopy)
+	}
+	return newBookCopyResponse(bookCopy), nil
+}
+
+func (u *usecase) DeleteBookCopy(bookId, id int) (*dto.BookCopyResponse, *response.ErrorResponse) {
+	var result *dto.BookCopyResponse
+
+	bookCopy, errs := u.getBookCopy(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	if bookCopy.Status == constant.COPY_STATUS_ON_LOAN || bookCopy.Status == constant.COPY_STATUS_ON_HOLD {
+		return result, response.NewErrorResponse(http.StatusConflict, constant.COPY_IN_USE)
+	}
+
+	if err := u.BookCopyRepository.DeleteBookCopy(bookCopy); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newBookCopyResponse(bookCopy), nil
+}
+
+// releaseCopy gives a copy that became available to the first member waiting
+// for its book.
+func (u *usecase) releaseCopy(bookCopy *model.BookCopy) (*dto.BookCopyResponse, *response.ErrorResponse) {
+	now := time.Now()
+	if err := u.HoldRepository.ReleaseCopy(bookCopy, now, now.Add(hold.PickupWindow())); err != nil {
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	bookCopy, err := u.BookCopyRepository.GetBookCopyById(int(bookCopy.BookID), int(bookCopy.ID))
+	if err != nil {
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return newBookCopyResponse(bookCopy), nil
+}
+
+func (u *usecase) checkBook(bookId int) *response.ErrorResponse {
+	_, err := u.BookRepository.GetBookById(bookId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return response.NewErrorResponse(http.StatusNotFound, errors.New("Book not found"))
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) getBookCopy(bookId, id int) (*model.BookCopy, *response.ErrorResponse) {
+	if errs := u.checkBook(bookId); errs != nil {
+		return nil, errs
+	}
+
+	bookCopy, err := u.BookCopyRepository.GetBookCopyById(bookId, id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, response.NewErrorResponse(http.StatusNotFound, errors.New("Copy not found"))
+		}
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return bookCopy, nil
+}
+
+// checkBarcode fails when a bookCopy other than copyId already has the barcode.
+func (u *usecase) checkBarcode(barcode string, copyId uint) *response.ErrorResponse {
+	if barcode == "" {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Barcode can't be empty"))
+	}
+
+	existing, err := u.BookCopyRepository.GetBookCopyByBarcode(barcode)
+	if err == nil && existing.ID != copyId {
+		return response.NewErrorResponse(http.StatusConflict, errors.New("Barcode already exists"))
+	}
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func newBookCopyResponse(bookCopy *model.BookCopy) *dto.BookCopyResponse {
+	return &dto.BookCopyResponse{
+		ID:            int(bookCopy.ID),
+		BookID:        int(bookCopy.BookID),
+		Barcode:       bookCopy.Barcode,
+		AcquiredAt:    bookCopy.AcquiredAt,
+		ShelfLocation: bookCopy.ShelfLocation,
+		Condition:     bookCopy.Condition,
+		Status:        bookCopy.Status,
+		CreatedAt:     bookCopy.CreatedAt,
+		UpdatedAt:     bookCopy.UpdatedAt,
+	}
+}
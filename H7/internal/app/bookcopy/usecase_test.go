@@ -0,0 +1,29 @@
+package bookcopy
+
+import (
+	"testing"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	usecaseTest = NewUsecase(factory.NewFactory())
+)
+
+func TestBookCopyUsecaseCreateBookCopyBookNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.CreateBookCopy(404, &dto.NewBookCopy{Barcode: "LIB-000404"})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Book not found")
+	}
+}
+
+func TestBookCopyUsecaseDeleteBookCopyNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.DeleteBookCopy(404, 404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Book not found")
+	}
+}
@@ -0,0 +1,98 @@
+package category
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) CreateCategory(c echo.Context) error {
+	var input dto.NewCategory
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.CreateCategory(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Create new category success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetAllCategories(c echo.Context) error {
+	res, errs := co.usecase.GetAllCategories()
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get all categories success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetCategoryById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetCategoryById(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get category by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) UpdateCategoryById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewCategory
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.UpdateCategory(id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Update category by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) DeleteCategoryById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.DeleteCategory(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Delete category by id success", res).SendSuccessResponse(c)
+}
@@ -0,0 +1,21 @@
+package category
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	canManage := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_BOOKS_WRITE),
+	}
+
+	e.GET("", c.GetAllCategories)
+	e.POST("", c.CreateCategory, canManage...)
+	e.GET("/:id", c.GetCategoryById)
+	e.PUT("/:id", c.UpdateCategoryById, canManage...)
+	e.DELETE("/:id", c.DeleteCategoryById, canManage...)
+}
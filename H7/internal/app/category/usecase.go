@@ -0,0 +1,207 @@
+package category
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CreateCategory(input *dto.NewCategory) (*dto.CategoryResponse, *response.ErrorResponse)
+	GetCategoryById(id int) (*dto.CategoryResponse, *response.ErrorResponse)
+	GetAllCategories() ([]*dto.CategoryResponse, *response.ErrorResponse)
+	UpdateCategory(id int, input *dto.NewCategory) (*dto.CategoryResponse, *response.ErrorResponse)
+	DeleteCategory(id int) (*dto.CategoryResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	CategoryRepository repository.CategoryRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		CategoryRepository: f.CategoryRepository,
+	}
+}
+
+func (u *usecase) CreateCategory(input *dto.NewCategory) (*dto.CategoryResponse, *response.ErrorResponse) {
+	var result *dto.CategoryResponse
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid category name"))
+	}
+
+	var parent *model.Category
+	if input.ParentID != nil && *input.ParentID != 0 {
+		var errs *response.ErrorResponse
+		if parent, errs = u.getParent(*input.ParentID); errs != nil {
+			return result, errs
+		}
+	}
+
+	if errs := u.checkSiblingName(parent, name, 0); errs != nil {
+		return result, errs
+	}
+
+	category, err := u.CategoryRepository.CreateCategory(&model.Category{Name: name}, parent)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newCategoryResponse(category), nil
+}
+
+func (u *usecase) GetCategoryById(id int) (*dto.CategoryResponse, *response.ErrorResponse) {
+	var result *dto.CategoryResponse
+
+	category, err := u.CategoryRepository.GetCategoryById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Category not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newCategoryResponse(category), nil
+}
+
+func (u *usecase) GetAllCategories() ([]*dto.CategoryResponse, *response.ErrorResponse) {
+	result := []*dto.CategoryResponse{}
+
+	categories, err := u.CategoryRepository.GetAllCategories()
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	for i := range categories {
+		result = append(result, newCategoryResponse(&categories[i]))
+	}
+
+	return result, nil
+}
+
+// UpdateCategory renames the category and moves it, with all of its
+// descendants, below another parent.
+func (u *usecase) UpdateCategory(id int, input *dto.NewCategory) (*dto.CategoryResponse, *response.ErrorResponse) {
+	var result *dto.CategoryResponse
+
+	category, err := u.CategoryRepository.GetCategoryById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Category not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid category name"))
+	}
+
+	parentId := 0
+	if input.ParentID != nil {
+		parentId = *input.ParentID
+	} else if category.ParentID != nil {
+		parentId = int(*category.ParentID)
+	}
+
+	var parent *model.Category
+	if parentId != 0 {
+		var errs *response.ErrorResponse
+		if parent, errs = u.getParent(parentId); errs != nil {
+			return result, errs
+		}
+		if strings.HasPrefix(parent.Path, category.Path) {
+			return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Category can't be moved below itself"))
+		}
+	}
+
+	if errs := u.checkSiblingName(parent, name, category.ID); errs != nil {
+		return result, errs
+	}
+
+	category.Name = name
+	category, err = u.CategoryRepository.MoveCategory(category, parent)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newCategoryResponse(category), nil
+}
+
+func (u *usecase) DeleteCategory(id int) (*dto.CategoryResponse, *response.ErrorResponse) {
+	var result *dto.CategoryResponse
+
+	category, err := u.CategoryRepository.GetCategoryById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Category not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	count, err := u.CategoryRepository.CountChildCategories(category)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if count > 0 {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Category has subcategories"))
+	}
+
+	if err := u.CategoryRepository.DeleteCategory(category); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newCategoryResponse(category), nil
+}
+
+func (u *usecase) getParent(id int) (*model.Category, *response.ErrorResponse) {
+	parent, err := u.CategoryRepository.GetCategoryById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, response.NewErrorResponse(http.StatusBadRequest, errors.New("Parent category not found"))
+		}
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return parent, nil
+}
+
+// checkSiblingName keeps paths such as "Fiction > Fantasy" unambiguous.
+func (u *usecase) checkSiblingName(parent *model.Category, name string, exceptId uint) *response.ErrorResponse {
+	var parentId *uint
+	if parent != nil {
+		parentId = &parent.ID
+	}
+
+	exists, err := u.CategoryRepository.CategoryNameExists(parentId, name, exceptId)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if exists {
+		return response.NewErrorResponse(http.StatusConflict, errors.New("Category already exists"))
+	}
+	return nil
+}
+
+func newCategoryResponse(category *model.Category) *dto.CategoryResponse {
+	var parentId *int
+	if category.ParentID != nil {
+		id := int(*category.ParentID)
+		parentId = &id
+	}
+
+	return &dto.CategoryResponse{
+		ID:       int(category.ID),
+		Name:     category.Name,
+		ParentID: parentId,
+		Path:     category.FullName,
+	}
+}
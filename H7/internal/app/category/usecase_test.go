@@ -0,0 +1,40 @@
+package category
+
+import (
+	"testing"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	usecaseTest = NewUsecase(factory.NewFactory())
+)
+
+func TestCategoryUsecaseMoveBelowItself(t *testing.T) {
+	asserts := assert.New(t)
+
+	fiction, err := usecaseTest.CreateCategory(&dto.NewCategory{Name: "Fiction Move Test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fantasy, err := usecaseTest.CreateCategory(&dto.NewCategory{Name: "Fantasy", ParentID: &fiction.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal("Fiction Move Test > Fantasy", fantasy.Path)
+
+	_, err = usecaseTest.UpdateCategory(fiction.ID, &dto.NewCategory{Name: fiction.Name, ParentID: &fantasy.ID})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Category can't be moved below itself")
+	}
+}
+
+func TestCategoryUsecaseGetCategoryByIdNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.GetCategoryById(404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Category not found")
+	}
+}
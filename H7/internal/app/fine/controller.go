@@ -0,0 +1,170 @@
+package fine
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) GetMyBalance(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	return co.sendBalance(c, int(principal.UserID))
+}
+
+func (co *controller) GetMyEntries(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	return co.sendEntries(c, int(principal.UserID))
+}
+
+func (co *controller) GetUserBalance(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	return co.sendBalance(c, id)
+}
+
+func (co *controller) GetUserEntries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	return co.sendEntries(c, id)
+}
+
+func (co *controller) RecordPayment(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewFinePayment
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.RecordPayment(principal, id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Record payment success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) AdjustBalance(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewFineAdjustment
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.AdjustBalance(principal, id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Adjust balance success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) WaiveEntry(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.NewFineWaiver
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.WaiveEntry(principal, id, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Waive fine success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) sendBalance(c echo.Context, userId int) error {
+	res, errs := co.usecase.GetBalance(userId)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get fine balance success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) sendEntries(c echo.Context, userId int) error {
+	var input dto.FineListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetEntries(userId, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get fine entries success", res.Entries).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
@@ -0,0 +1,70 @@
+package fine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util"
+)
+
+// Fine amounts are configured in cents.
+
+func OverdueDailyRate() int64 {
+	return int64(util.GetenvInt("FINE_OVERDUE_DAILY_RATE", 25))
+}
+
+// OverdueCap is the most a single loan is charged for being late.
+func OverdueCap() int64 {
+	return int64(util.GetenvInt("FINE_OVERDUE_CAP", 1000))
+}
+
+func LostItemCharge() int64 {
+	return int64(util.GetenvInt("FINE_LOST_ITEM_CHARGE", 2500))
+}
+
+// CheckoutThreshold is the balance above which new checkouts are refused.
+func CheckoutThreshold() int64 {
+	return int64(util.GetenvInt("FINE_CHECKOUT_THRESHOLD", 1000))
+}
+
+// ReturnFines charges a loan returned at at for every started day it's late,
+// up to OverdueCap.
+func ReturnFines(loan *model.Loan, at time.Time) []model.FineEntry {
+	days := loan.DaysOverdue(at)
+	if days == 0 {
+		return nil
+	}
+
+	amount := int64(days) * OverdueDailyRate()
+	if limit := OverdueCap(); limit > 0 && amount > limit {
+		amount = limit
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	return []model.FineEntry{newLoanFine(loan, constant.FINE_TYPE_OVERDUE, amount, fmt.Sprintf("%d days overdue", days))}
+}
+
+// LostFines charges the late days of a loan whose copy was lost on top of the
+// copy itself.
+func LostFines(loan *model.Loan, at time.Time) []model.FineEntry {
+	fines := ReturnFines(loan, at)
+	if amount := LostItemCharge(); amount > 0 {
+		fines = append(fines, newLoanFine(loan, constant.FINE_TYPE_LOST_ITEM, amount, "Lost copy "+loan.BookCopy.Barcode))
+	}
+	return fines
+}
+
+func newLoanFine(loan *model.Loan, entryType string, amount int64, note string) model.FineEntry {
+	loanId := loan.ID
+	return model.FineEntry{
+		UserID: loan.UserID,
+		LoanID: &loanId,
+		Type:   entryType,
+		Amount: amount,
+		Note:   note,
+	}
+}
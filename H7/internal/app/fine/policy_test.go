@@ -0,0 +1,62 @@
+package fine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinePolicyReturnFines(t *testing.T) {
+	asserts := assert.New(t)
+	t.Setenv("FINE_OVERDUE_DAILY_RATE", "25")
+	t.Setenv("FINE_OVERDUE_CAP", "1000")
+
+	due := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+	loan := &model.Loan{Model: gorm.Model{ID: 7}, UserID: 3, DueAt: due}
+
+	asserts.Empty(ReturnFines(loan, due))
+
+	fines := ReturnFines(loan, due.Add(49*time.Hour))
+	if asserts.Len(fines, 1) {
+		asserts.Equal(uint(3), fines[0].UserID)
+		asserts.Equal(uint(7), *fines[0].LoanID)
+		asserts.Equal(constant.FINE_TYPE_OVERDUE, fines[0].Type)
+		asserts.Equal(int64(75), fines[0].Amount)
+		asserts.Equal("3 days overdue", fines[0].Note)
+	}
+
+	// a long overdue loan is charged the cap
+	fines = ReturnFines(loan, due.AddDate(0, 0, 60))
+	if asserts.Len(fines, 1) {
+		asserts.Equal(int64(1000), fines[0].Amount)
+	}
+}
+
+func TestFinePolicyLostFines(t *testing.T) {
+	asserts := assert.New(t)
+	t.Setenv("FINE_OVERDUE_DAILY_RATE", "25")
+	t.Setenv("FINE_OVERDUE_CAP", "1000")
+	t.Setenv("FINE_LOST_ITEM_CHARGE", "2500")
+
+	due := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+	loan := &model.Loan{Model: gorm.Model{ID: 7}, UserID: 3, DueAt: due, BookCopy: model.BookCopy{Barcode: "LIB-0042"}}
+
+	fines := LostFines(loan, due.Add(-time.Hour))
+	if asserts.Len(fines, 1) {
+		asserts.Equal(constant.FINE_TYPE_LOST_ITEM, fines[0].Type)
+		asserts.Equal(int64(2500), fines[0].Amount)
+		asserts.Equal("Lost copy LIB-0042", fines[0].Note)
+	}
+
+	// lost after the due date, the late days are charged too
+	fines = LostFines(loan, due.AddDate(0, 0, 2))
+	if asserts.Len(fines, 2) {
+		asserts.Equal(constant.FINE_TYPE_OVERDUE, fines[0].Type)
+		asserts.Equal(int64(50), fines[0].Amount)
+		asserts.Equal(constant.FINE_TYPE_LOST_ITEM, fines[1].Type)
+	}
+}
@@ -0,0 +1,22 @@
+package fine
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	isStaff := middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN)
+	canRead := middleware.RequireScope(constant.SCOPE_LOANS_READ)
+	canWrite := middleware.RequireScope(constant.SCOPE_LOANS_WRITE)
+
+	e.Use(c.authMiddleware)
+	e.GET("/me", c.GetMyBalance, canRead)
+	e.GET("/me/entries", c.GetMyEntries, canRead)
+	e.GET("/users/:id", c.GetUserBalance, isStaff, canRead)
+	e.GET("/users/:id/entries", c.GetUserEntries, isStaff, canRead)
+	e.POST("/users/:id/payments", c.RecordPayment, isStaff, canWrite)
+	e.POST("/users/:id/adjustments", c.AdjustBalance, isStaff, canWrite)
+	e.POST("/entries/:id/waive", c.WaiveEntry, isStaff, canWrite)
+}
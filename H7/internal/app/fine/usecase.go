@@ -0,0 +1,190 @@
+package fine
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	GetBalance(userId int) (*dto.FineBalanceResponse, *response.ErrorResponse)
+	GetEntries(userId int, input *dto.FineListRequest) (*dto.FineList, *response.ErrorResponse)
+	RecordPayment(principal *jwtUtil.Principal, userId int, input *dto.NewFinePayment) (*dto.FineEntryResponse, *response.ErrorResponse)
+	AdjustBalance(principal *jwtUtil.Principal, userId int, input *dto.NewFineAdjustment) (*dto.FineEntryResponse, *response.ErrorResponse)
+	WaiveEntry(principal *jwtUtil.Principal, id int, input *dto.NewFineWaiver) (*dto.FineEntryResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	UserRepository repository.UserRepositoryInterface
+	FineRepository repository.FineRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		UserRepository: f.UserRepository,
+		FineRepository: f.FineRepository,
+	}
+}
+
+func (u *usecase) GetBalance(userId int) (*dto.FineBalanceResponse, *response.ErrorResponse) {
+	var result *dto.FineBalanceResponse
+
+	if errs := u.checkUser(userId); errs != nil {
+		return result, errs
+	}
+
+	balance, err := u.FineRepository.GetBalance(uint(userId))
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	threshold := CheckoutThreshold()
+	result = &dto.FineBalanceResponse{
+		UserID:          userId,
+		Balance:         balance,
+		Threshold:       threshold,
+		CheckoutBlocked: balance > threshold,
+	}
+	return result, nil
+}
+
+func (u *usecase) GetEntries(userId int, input *dto.FineListRequest) (*dto.FineList, *response.ErrorResponse) {
+	var result *dto.FineList
+
+	if errs := u.checkUser(userId); errs != nil {
+		return result, errs
+	}
+
+	offset, limit := input.Window()
+	entries, total, err := u.FineRepository.GetFineEntries(uint(userId), input.Type, offset, limit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.FineList{
+		Entries: []*dto.FineEntryResponse{},
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+	}
+	for i := range entries {
+		result.Entries = append(result.Entries, newFineEntryResponse(&entries[i]))
+	}
+
+	return result, nil
+}
+
+func (u *usecase) RecordPayment(principal *jwtUtil.Principal, userId int, input *dto.NewFinePayment) (*dto.FineEntryResponse, *response.ErrorResponse) {
+	return u.createEntry(principal, userId, &model.FineEntry{
+		Type:   constant.FINE_TYPE_PAYMENT,
+		Amount: -input.Amount,
+		Note:   input.Note,
+	})
+}
+
+func (u *usecase) AdjustBalance(principal *jwtUtil.Principal, userId int, input *dto.NewFineAdjustment) (*dto.FineEntryResponse, *response.ErrorResponse) {
+	return u.createEntry(principal, userId, &model.FineEntry{
+		Type:   constant.FINE_TYPE_ADJUSTMENT,
+		Amount: input.Amount,
+		Note:   input.Note,
+	})
+}
+
+func (u *usecase) WaiveEntry(principal *jwtUtil.Principal, id int, input *dto.NewFineWaiver) (*dto.FineEntryResponse, *response.ErrorResponse) {
+	var result *dto.FineEntryResponse
+
+	charge, err := u.FineRepository.GetFineEntryById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Fine entry not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if !charge.IsCharge() {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Only charges can be waived"))
+	}
+
+	amount := charge.Amount
+	if input.Amount != 0 {
+		if input.Amount > charge.Amount {
+			return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Waiver can't exceed the charge"))
+		}
+		amount = input.Amount
+	}
+
+	_, err = u.FineRepository.GetWaiver(charge)
+	if err == nil {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Charge already waived"))
+	}
+	if err != constant.RECORD_NOT_FOUND {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return u.createEntry(principal, int(charge.UserID), &model.FineEntry{
+		LoanID:         charge.LoanID,
+		Type:           constant.FINE_TYPE_WAIVER,
+		Amount:         -amount,
+		Note:           input.Note,
+		RelatedEntryID: &charge.ID,
+	})
+}
+
+func (u *usecase) createEntry(principal *jwtUtil.Principal, userId int, entry *model.FineEntry) (*dto.FineEntryResponse, *response.ErrorResponse) {
+	var result *dto.FineEntryResponse
+
+	if errs := u.checkUser(userId); errs != nil {
+		return result, errs
+	}
+
+	entry.UserID = uint(userId)
+	entry.CreatedByID = &principal.UserID
+	entry, err := u.FineRepository.CreateFineEntry(entry)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newFineEntryResponse(entry), nil
+}
+
+func (u *usecase) checkUser(userId int) *response.ErrorResponse {
+	_, err := u.UserRepository.GetUserById(userId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func newFineEntryResponse(entry *model.FineEntry) *dto.FineEntryResponse {
+	result := &dto.FineEntryResponse{
+		ID:        int(entry.ID),
+		UserID:    int(entry.UserID),
+		Type:      entry.Type,
+		Amount:    entry.Amount,
+		Note:      entry.Note,
+		CreatedAt: entry.CreatedAt,
+	}
+	if entry.LoanID != nil {
+		loanId := int(*entry.LoanID)
+		result.LoanID = &loanId
+	}
+	if entry.RelatedEntryID != nil {
+		relatedId := int(*entry.RelatedEntryID)
+		result.RelatedEntryID = &relatedId
+	}
+	if entry.CreatedByID != nil {
+		createdById := int(*entry.CreatedByID)
+		result.CreatedByID = &createdById
+	}
+	return result
+}
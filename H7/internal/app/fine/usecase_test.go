@@ -0,0 +1,30 @@
+package fine
+
+import (
+	"testing"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	usecaseTest = NewUsecase(factory.NewFactory())
+)
+
+func TestFineUsecaseGetBalanceUserNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.GetBalance(404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "User not found")
+	}
+}
+
+func TestFineUsecaseWaiveEntryNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.WaiveEntry(&jwtUtil.Principal{UserID: 1}, 404, &dto.NewFineWaiver{Note: "first offence"})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Fine entry not found")
+	}
+}
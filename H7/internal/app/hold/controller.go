@@ -0,0 +1,127 @@
+package hold
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) PlaceHold(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.NewHold
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.PlaceHold(principal, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Place hold success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetAllHolds(c echo.Context) error {
+	var input dto.HoldListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAllHolds(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get all holds success", res.Holds).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) GetUserHolds(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.HoldListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetUserHolds(principal, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get user holds success", res.Holds).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) GetHoldById(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetHoldById(principal, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get hold by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) CancelHold(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.CancelHold(principal, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Cancel hold success", res).SendSuccessResponse(c)
+}
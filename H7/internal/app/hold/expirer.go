@@ -0,0 +1,31 @@
+package hold
+
+import (
+	"log"
+	"time"
+
+	"github.com/hansandika/internal/factory"
+)
+
+// StartExpirer expires holds, once right away and then on every interval,
+// until the process exits. Copies of expired ready holds go to the next
+// member in the queue.
+func StartExpirer(f *factory.Factory, interval time.Duration) {
+	u := NewUsecase(f)
+	run := func() {
+		count, err := u.ExpireHolds()
+		if err != nil {
+			log.Printf("expiring holds: %v", err)
+		}
+		if count > 0 {
+			log.Printf("expired %d holds", count)
+		}
+	}
+
+	go func() {
+		run()
+		for range time.Tick(interval) {
+			run()
+		}
+	}()
+}
@@ -0,0 +1,20 @@
+package hold
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	isStaff := middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN)
+	canRead := middleware.RequireScope(constant.SCOPE_LOANS_READ)
+	canWrite := middleware.RequireScope(constant.SCOPE_LOANS_WRITE)
+
+	e.Use(c.authMiddleware)
+	e.POST("", c.PlaceHold, canWrite)
+	e.GET("", c.GetAllHolds, isStaff, canRead)
+	e.GET("/me", c.GetUserHolds, canRead)
+	e.GET("/:id", c.GetHoldById, canRead)
+	e.DELETE("/:id", c.CancelHold, canWrite)
+}
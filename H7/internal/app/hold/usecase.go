@@ -0,0 +1,238 @@
+package hold
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	PlaceHold(principal *jwtUtil.Principal, input *dto.NewHold) (*dto.HoldResponse, *response.ErrorResponse)
+	GetHoldById(principal *jwtUtil.Principal, id int) (*dto.HoldResponse, *response.ErrorResponse)
+	GetAllHolds(input *dto.HoldListRequest) (*dto.HoldList, *response.ErrorResponse)
+	GetUserHolds(principal *jwtUtil.Principal, input *dto.HoldListRequest) (*dto.HoldList, *response.ErrorResponse)
+	CancelHold(principal *jwtUtil.Principal, id int) (*dto.HoldResponse, *response.ErrorResponse)
+	ExpireHolds() (int, error)
+}
+
+type usecase struct {
+	UserRepository repository.UserRepositoryInterface
+	BookRepository repository.BookRepositoryInterface
+	LoanRepository repository.LoanRepositoryInterface
+	HoldRepository repository.HoldRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		UserRepository: f.UserRepository,
+		BookRepository: f.BookRepository,
+		LoanRepository: f.LoanRepository,
+		HoldRepository: f.HoldRepository,
+	}
+}
+
+// PickupWindow is how long a ready hold keeps its copy.
+func PickupWindow() time.Duration {
+	return time.Duration(util.GetenvInt("HOLD_PICKUP_DAYS", 3)) * 24 * time.Hour
+}
+
+// HoldExpiry is how long a hold waits in the queue unless the member picks
+// another expiry.
+func HoldExpiry() time.Duration {
+	return time.Duration(util.GetenvInt("HOLD_EXPIRY_DAYS", 180)) * 24 * time.Hour
+}
+
+func (u *usecase) PlaceHold(principal *jwtUtil.Principal, input *dto.NewHold) (*dto.HoldResponse, *response.ErrorResponse) {
+	var result *dto.HoldResponse
+
+	userId := int(principal.UserID)
+	if input.UserID != 0 && input.UserID != userId {
+		if !principal.IsStaff() {
+			return result, response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized"))
+		}
+		userId = input.UserID
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(HoldExpiry())
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(now) {
+			return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Hold expiry must be in the future"))
+		}
+		expiresAt = *input.ExpiresAt
+	}
+
+	user, err := u.UserRepository.GetUserById(userId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if user.DeletionScheduledAt != nil {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Account is scheduled for deletion"))
+	}
+
+	if _, err := u.BookRepository.GetBookById(input.BookID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Book not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	active, err := u.LoanRepository.CountActiveLoans(user.ID, uint(input.BookID))
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if active > 0 {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Book is already on loan to this user"))
+	}
+
+	hold, err := u.HoldRepository.CreateHold(&model.Hold{
+		UserID:    user.ID,
+		BookID:    uint(input.BookID),
+		Status:    constant.HOLD_STATUS_WAITING,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if err == constant.HOLD_EXISTS {
+			return result, response.NewErrorResponse(http.StatusConflict, err)
+		}
+		if err == constant.COPY_AVAILABLE {
+			return result, response.NewErrorResponse(http.StatusConflict, errors.New("Book has available copies, check one out instead"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return u.newHoldResponse(hold)
+}
+
+func (u *usecase) GetHoldById(principal *jwtUtil.Principal, id int) (*dto.HoldResponse, *response.ErrorResponse) {
+	hold, errs := u.getHold(principal, id)
+	if errs != nil {
+		return nil, errs
+	}
+
+	return u.newHoldResponse(hold)
+}
+
+func (u *usecase) GetAllHolds(input *dto.HoldListRequest) (*dto.HoldList, *response.ErrorResponse) {
+	return u.getHolds(uint(input.UserID), input)
+}
+
+func (u *usecase) GetUserHolds(principal *jwtUtil.Principal, input *dto.HoldListRequest) (*dto.HoldList, *response.ErrorResponse) {
+	return u.getHolds(principal.UserID, input)
+}
+
+func (u *usecase) CancelHold(principal *jwtUtil.Principal, id int) (*dto.HoldResponse, *response.ErrorResponse) {
+	var result *dto.HoldResponse
+
+	hold, errs := u.getHold(principal, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	now := time.Now()
+	if err := u.HoldRepository.CancelHold(hold, now, now.Add(PickupWindow())); err != nil {
+		if err == constant.HOLD_NOT_ACTIVE {
+			return result, response.NewErrorResponse(http.StatusConflict, err)
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return u.newHoldResponse(hold)
+}
+
+// ExpireHolds ends the holds that expired in the queue or weren't picked up
+// in time and returns how many there were.
+func (u *usecase) ExpireHolds() (int, error) {
+	now := time.Now()
+	return u.HoldRepository.ExpireHolds(now, now.Add(PickupWindow()))
+}
+
+// getHold only returns holds of the caller, unless the caller is staff.
+func (u *usecase) getHold(principal *jwtUtil.Principal, id int) (*model.Hold, *response.ErrorResponse) {
+	hold, err := u.HoldRepository.GetHoldById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, response.NewErrorResponse(http.StatusNotFound, errors.New("Hold not found"))
+		}
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if hold.UserID != principal.UserID && !principal.IsStaff() {
+		return nil, response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized"))
+	}
+	return hold, nil
+}
+
+func (u *usecase) getHolds(userId uint, input *dto.HoldListRequest) (*dto.HoldList, *response.ErrorResponse) {
+	var result *dto.HoldList
+
+	offset, limit := input.Window()
+	holds, total, err := u.HoldRepository.GetHolds(&repository.HoldFilter{
+		UserID: userId,
+		BookID: uint(input.BookID),
+		Status: input.Status,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.HoldList{
+		Holds:  []*dto.HoldResponse{},
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+	for i := range holds {
+		res, errs := u.newHoldResponse(&holds[i])
+		if errs != nil {
+			return nil, errs
+		}
+		result.Holds = append(result.Holds, res)
+	}
+
+	return result, nil
+}
+
+func (u *usecase) newHoldResponse(hold *model.Hold) (*dto.HoldResponse, *response.ErrorResponse) {
+	result := &dto.HoldResponse{
+		ID:        int(hold.ID),
+		UserID:    int(hold.UserID),
+		BookID:    int(hold.BookID),
+		BookTitle: hold.Book.Title,
+		Status:    hold.Status,
+		CreatedAt: hold.CreatedAt,
+		ExpiresAt: hold.ExpiresAt,
+		ReadyAt:   hold.ReadyAt,
+		PickupBy:  hold.PickupBy,
+	}
+
+	if hold.Status == constant.HOLD_STATUS_WAITING {
+		ahead, err := u.HoldRepository.CountHoldsAhead(hold, time.Now())
+		if err != nil {
+			return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		result.Position = int(ahead) + 1
+	}
+
+	if hold.Status == constant.HOLD_STATUS_READY && hold.BookCopyID != nil {
+		copyId := int(*hold.BookCopyID)
+		result.CopyID = &copyId
+		result.Barcode = hold.BookCopy.Barcode
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package hold
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	factoryTest = factory.NewFactory()
+	usecaseTest = NewUsecase(factoryTest)
+)
+
+func TestHoldUsecasePlaceHoldExpiryInPast(t *testing.T) {
+	asserts := assert.New(t)
+	expiresAt := time.Now().Add(-time.Hour)
+	_, err := usecaseTest.PlaceHold(&jwtUtil.Principal{UserID: 1}, &dto.NewHold{BookID: 1, ExpiresAt: &expiresAt})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Hold expiry must be in the future")
+	}
+}
+
+func TestHoldUsecaseCancelHoldNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.CancelHold(&jwtUtil.Principal{UserID: 1}, 404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Hold not found")
+	}
+}
+
+func TestHoldUsecaseReturnedCopyServesQueueInOrder(t *testing.T) {
+	asserts := assert.New(t)
+	now := time.Now()
+	pickupBy := now.Add(PickupWindow())
+
+	book, err := factoryTest.BookRepository.CreateNewBook(&model.Book{Title: "Hold queue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factoryTest.BookRepository.DeleteBook(book)
+
+	bookCopy, err := factoryTest.BookCopyRepository.CreateBookCopy(&model.BookCopy{
+		BookID:  book.ID,
+		Barcode: fmt.Sprintf("QUEUE-%d", now.UnixNano()),
+		Status:  constant.COPY_STATUS_AVAILABLE,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loan, err := factoryTest.LoanRepository.CheckoutBook(&model.Loan{UserID: 899999, BookID: book.ID, DueAt: now}, "", pickupBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first hold expired before the copy came back and is skipped
+	expiries := []time.Time{now.Add(-time.Hour), now.Add(time.Hour), now.Add(time.Hour)}
+	holds := []*model.Hold{}
+	for i, expiresAt := range expiries {
+		hold, err := factoryTest.HoldRepository.CreateHold(&model.Hold{
+			UserID:    uint(900000 + i),
+			BookID:    book.ID,
+			Status:    constant.HOLD_STATUS_WAITING,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		holds = append(holds, hold)
+	}
+	defer func() {
+		for _, hold := range holds {
+			factoryTest.HoldRepository.CancelHold(hold, now, pickupBy)
+		}
+	}()
+
+	if err := factoryTest.LoanRepository.ReturnLoan(loan, now, pickupBy, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := func() []string {
+		result := []string{}
+		for _, hold := range holds {
+			current, err := factoryTest.HoldRepository.GetHoldById(int(hold.ID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, current.Status)
+		}
+		return result
+	}
+	asserts.Equal([]string{constant.HOLD_STATUS_WAITING, constant.HOLD_STATUS_READY, constant.HOLD_STATUS_WAITING}, statuses())
+
+	// a cancelled ready hold passes the copy on to the next one in line
+	if err := factoryTest.HoldRepository.CancelHold(holds[1], now, pickupBy); err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal([]string{constant.HOLD_STATUS_WAITING, constant.HOLD_STATUS_CANCELLED, constant.HOLD_STATUS_READY}, statuses())
+
+	ready, err := factoryTest.HoldRepository.GetHoldById(int(holds[2].ID))
+	if asserts.NoError(err) && asserts.NotNil(ready.BookCopyID) {
+		asserts.Equal(bookCopy.ID, *ready.BookCopyID)
+	}
+}
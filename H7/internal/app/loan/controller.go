@@ -0,0 +1,153 @@
+package loan
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+type controller struct {
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
+}
+
+func NewController(f *factory.Factory) *controller {
+	return &controller{
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
+	}
+}
+
+func (co *controller) CheckoutBook(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.NewLoan
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.CheckoutBook(principal, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusCreated, "Checkout book success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) GetAllLoans(c echo.Context) error {
+	var input dto.LoanListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetAllLoans(&input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get all loans success", res.Loans).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) GetUserLoans(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	var input dto.LoanListRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetUserLoans(principal, &input)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get user loans success", res.Loans).
+		WithPagination(response.NewPagination(c.Request().URL, res.Offset, res.Limit, res.Total)).
+		SendSuccessResponse(c)
+}
+
+func (co *controller) GetLoanById(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GetLoanById(principal, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Get loan by id success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) ReturnLoan(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.ReturnLoan(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Return loan success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) MarkLoanLost(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.MarkLoanLost(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Mark loan lost success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) RenewLoan(c echo.Context) error {
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.RenewLoan(principal, id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Renew loan success", res).SendSuccessResponse(c)
+}
@@ -0,0 +1,22 @@
+package loan
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	isStaff := middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN)
+	canRead := middleware.RequireScope(constant.SCOPE_LOANS_READ)
+	canWrite := middleware.RequireScope(constant.SCOPE_LOANS_WRITE)
+
+	e.Use(c.authMiddleware)
+	e.POST("", c.CheckoutBook, canWrite)
+	e.GET("", c.GetAllLoans, isStaff, canRead)
+	e.GET("/me", c.GetUserLoans, canRead)
+	e.GET("/:id", c.GetLoanById, canRead)
+	e.POST("/:id/return", c.ReturnLoan, isStaff, canWrite)
+	e.POST("/:id/lost", c.MarkLoanLost, isStaff, canWrite)
+	e.POST("/:id/renew", c.RenewLoan, canWrite)
+}
@@ -0,0 +1,297 @@
+package loan
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hansandika/internal/app/fine"
+	"github.com/hansandika/internal/app/hold"
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CheckoutBook(principal *jwtUtil.Principal, input *dto.NewLoan) (*dto.LoanResponse, *response.ErrorResponse)
+	GetLoanById(principal *jwtUtil.Principal, id int) (*dto.LoanResponse, *response.ErrorResponse)
+	GetAllLoans(input *dto.LoanListRequest) (*dto.LoanList, *response.ErrorResponse)
+	GetUserLoans(principal *jwtUtil.Principal, input *dto.LoanListRequest) (*dto.LoanList, *response.ErrorResponse)
+	ReturnLoan(id int) (*dto.LoanResponse, *response.ErrorResponse)
+	MarkLoanLost(id int) (*dto.LoanResponse, *response.ErrorResponse)
+	RenewLoan(principal *jwtUtil.Principal, id int) (*dto.LoanResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	UserRepository repository.UserRepositoryInterface
+	BookRepository repository.BookRepositoryInterface
+	LoanRepository repository.LoanRepositoryInterface
+	HoldRepository repository.HoldRepositoryInterface
+	FineRepository repository.FineRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		UserRepository: f.UserRepository,
+		BookRepository: f.BookRepository,
+		LoanRepository: f.LoanRepository,
+		HoldRepository: f.HoldRepository,
+		FineRepository: f.FineRepository,
+	}
+}
+
+// LoanPeriod is how long a checkout or a renewal lends a copy for.
+func LoanPeriod() time.Duration {
+	return time.Duration(util.GetenvInt("LOAN_PERIOD_DAYS", 14)) * 24 * time.Hour
+}
+
+// MaxRenewals is how many times a loan may be renewed.
+func MaxRenewals() int {
+	return util.GetenvInt("LOAN_MAX_RENEWALS", 2)
+}
+
+func (u *usecase) CheckoutBook(principal *jwtUtil.Principal, input *dto.NewLoan) (*dto.LoanResponse, *response.ErrorResponse) {
+	var result *dto.LoanResponse
+
+	userId := int(principal.UserID)
+	if input.UserID != 0 && input.UserID != userId {
+		if !principal.IsStaff() {
+			return result, response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized"))
+		}
+		userId = input.UserID
+	}
+
+	user, err := u.UserRepository.GetUserById(userId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if user.DeletionScheduledAt != nil {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Account is scheduled for deletion"))
+	}
+
+	if _, err := u.BookRepository.GetBookById(input.BookID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Book not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	balance, err := u.FineRepository.GetBalance(user.ID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if balance > fine.CheckoutThreshold() {
+		return result, response.NewErrorResponse(http.StatusForbidden, errors.New("Outstanding fines block new checkouts"))
+	}
+
+	active, err := u.LoanRepository.CountActiveLoans(user.ID, uint(input.BookID))
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if active > 0 {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Book is already on loan to this user"))
+	}
+
+	now := time.Now()
+	loan, err := u.LoanRepository.CheckoutBook(&model.Loan{
+		UserID: user.ID,
+		BookID: uint(input.BookID),
+		DueAt:  now.Add(LoanPeriod()),
+	}, strings.TrimSpace(input.Barcode), now.Add(hold.PickupWindow()))
+	if err != nil {
+		if err == constant.NO_COPY_AVAILABLE || err == constant.COPY_IN_USE {
+			return result, response.NewErrorResponse(http.StatusConflict, err)
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newLoanResponse(loan, now), nil
+}
+
+func (u *usecase) GetLoanById(principal *jwtUtil.Principal, id int) (*dto.LoanResponse, *response.ErrorResponse) {
+	var result *dto.LoanResponse
+
+	loan, errs := u.getLoan(principal, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	return newLoanResponse(loan, time.Now()), nil
+}
+
+func (u *usecase) GetAllLoans(input *dto.LoanListRequest) (*dto.LoanList, *response.ErrorResponse) {
+	return u.getLoans(uint(input.UserID), input)
+}
+
+func (u *usecase) GetUserLoans(principal *jwtUtil.Principal, input *dto.LoanListRequest) (*dto.LoanList, *response.ErrorResponse) {
+	return u.getLoans(principal.UserID, input)
+}
+
+// ReturnLoan charges the overdue fee of a late loan.
+func (u *usecase) ReturnLoan(id int) (*dto.LoanResponse, *response.ErrorResponse) {
+	var result *dto.LoanResponse
+
+	loan, errs := u.getActiveLoan(id)
+	if errs != nil {
+		return result, errs
+	}
+
+	now := time.Now()
+	if err := u.LoanRepository.ReturnLoan(loan, now, now.Add(hold.PickupWindow()), fine.ReturnFines(loan, now)); err != nil {
+		if err == constant.LOAN_NOT_ACTIVE {
+			return result, response.NewErrorResponse(http.StatusConflict, errors.New("Loan already returned"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newLoanResponse(loan, now), nil
+}
+
+// MarkLoanLost closes a loan whose copy the member lost, charging the copy
+// and the overdue fee.
+func (u *usecase) MarkLoanLost(id int) (*dto.LoanResponse, *response.ErrorResponse) {
+	var result *dto.LoanResponse
+
+	loan, errs := u.getActiveLoan(id)
+	if errs != nil {
+		return result, errs
+	}
+
+	now := time.Now()
+	if err := u.LoanRepository.MarkLoanLost(loan, now, fine.LostFines(loan, now)); err != nil {
+		if err == constant.LOAN_NOT_ACTIVE {
+			return result, response.NewErrorResponse(http.StatusConflict, errors.New("Loan already returned"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newLoanResponse(loan, now), nil
+}
+
+func (u *usecase) RenewLoan(principal *jwtUtil.Principal, id int) (*dto.LoanResponse, *response.ErrorResponse) {
+	var result *dto.LoanResponse
+
+	loan, errs := u.getLoan(principal, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	now := time.Now()
+	if !loan.IsActive() {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Loan already returned"))
+	}
+	if loan.IsOverdue(now) {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Overdue loans can't be renewed"))
+	}
+	if loan.Renewals >= MaxRenewals() {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Renewal limit reached"))
+	}
+
+	_, waiting, err := u.HoldRepository.GetHolds(&repository.HoldFilter{
+		BookID: loan.BookID,
+		Status: constant.HOLD_STATUS_WAITING,
+		Limit:  1,
+	})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if waiting > 0 {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("Other members are waiting for this book"))
+	}
+
+	if err := u.LoanRepository.RenewLoan(loan, now.Add(LoanPeriod())); err != nil {
+		if err == constant.LOAN_NOT_ACTIVE {
+			return result, response.NewErrorResponse(http.StatusConflict, errors.New("Loan changed, try again"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newLoanResponse(loan, now), nil
+}
+
+// getLoan only returns loans of the caller, unless the caller is staff.
+func (u *usecase) getLoan(principal *jwtUtil.Principal, id int) (*model.Loan, *response.ErrorResponse) {
+	loan, err := u.LoanRepository.GetLoanById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, response.NewErrorResponse(http.StatusNotFound, errors.New("Loan not found"))
+		}
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if loan.UserID != principal.UserID && !principal.IsStaff() {
+		return nil, response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized"))
+	}
+	return loan, nil
+}
+
+func (u *usecase) getActiveLoan(id int) (*model.Loan, *response.ErrorResponse) {
+	loan, err := u.LoanRepository.GetLoanById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, response.NewErrorResponse(http.StatusNotFound, errors.New("Loan not found"))
+		}
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if !loan.IsActive() {
+		return nil, response.NewErrorResponse(http.StatusConflict, errors.New("Loan already returned"))
+	}
+	return loan, nil
+}
+
+func (u *usecase) getLoans(userId uint, input *dto.LoanListRequest) (*dto.LoanList, *response.ErrorResponse) {
+	var result *dto.LoanList
+
+	now := time.Now()
+	offset, limit := input.Window()
+	loans, total, err := u.LoanRepository.GetLoans(&repository.LoanFilter{
+		UserID: userId,
+		BookID: uint(input.BookID),
+		Status: input.Status,
+		Now:    now,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.LoanList{
+		Loans:  []*dto.LoanResponse{},
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+	for i := range loans {
+		result.Loans = append(result.Loans, newLoanResponse(&loans[i], now))
+	}
+
+	return result, nil
+}
+
+func newLoanResponse(loan *model.Loan, now time.Time) *dto.LoanResponse {
+	return &dto.LoanResponse{
+		ID:         int(loan.ID),
+		UserID:     int(loan.UserID),
+		BookID:     int(loan.BookID),
+		BookTitle:  loan.Book.Title,
+		CopyID:     int(loan.BookCopyID),
+		Barcode:    loan.BookCopy.Barcode,
+		CreatedAt:  loan.CreatedAt,
+		DueAt:      loan.DueAt,
+		ReturnedAt: loan.ReturnedAt,
+		Renewals:   loan.Renewals,
+		Lost:       loan.Lost,
+		Overdue:    loan.IsOverdue(now),
+	}
+}
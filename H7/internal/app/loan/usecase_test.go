@@ -0,0 +1,87 @@
+package loan
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	factoryTest = factory.NewFactory()
+	usecaseTest = NewUsecase(factoryTest)
+)
+
+func TestLoanUsecaseCheckoutForOtherUserUnauthorized(t *testing.T) {
+	asserts := assert.New(t)
+	principal := &jwtUtil.Principal{UserID: 1, Roles: []string{constant.ROLE_MEMBER}}
+	_, err := usecaseTest.CheckoutBook(principal, &dto.NewLoan{BookID: 1, UserID: 2})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "This action is unauthorized")
+	}
+}
+
+func TestLoanUsecaseReturnLoanNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.ReturnLoan(404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Loan not found")
+	}
+}
+
+func TestLoanUsecaseConcurrentCheckoutOfLastCopy(t *testing.T) {
+	asserts := assert.New(t)
+	now := time.Now()
+
+	book, err := factoryTest.BookRepository.CreateNewBook(&model.Book{Title: "Concurrent checkout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factoryTest.BookRepository.DeleteBook(book)
+
+	_, err = factoryTest.BookCopyRepository.CreateBookCopy(&model.BookCopy{
+		BookID:  book.ID,
+		Barcode: fmt.Sprintf("RACE-%d", now.UnixNano()),
+		Status:  constant.COPY_STATUS_AVAILABLE,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every member asks for the only copy at once
+	const members = 8
+	loans := make([]*model.Loan, members)
+	errs := make([]error, members)
+	var wg sync.WaitGroup
+	for i := 0; i < members; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loans[i], errs[i] = factoryTest.LoanRepository.CheckoutBook(&model.Loan{
+				UserID: uint(900000 + i),
+				BookID: book.ID,
+				DueAt:  now.Add(LoanPeriod()),
+			}, "", now.Add(24*time.Hour))
+		}(i)
+	}
+	wg.Wait()
+
+	var lent []*model.Loan
+	for i := range errs {
+		if errs[i] == nil {
+			lent = append(lent, loans[i])
+			continue
+		}
+		asserts.Equal(constant.NO_COPY_AVAILABLE, errs[i])
+	}
+	if asserts.Len(lent, 1) {
+		asserts.NoError(factoryTest.LoanRepository.ReturnLoan(lent[0], now, now.Add(24*time.Hour), nil))
+	}
+}
@@ -0,0 +1,22 @@
+package review
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	canWrite := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireScope(constant.SCOPE_REVIEWS_WRITE),
+	}
+
+	e.GET("", c.GetReviews)
+	e.POST("", c.CreateReview, canWrite...)
+	e.GET("/:reviewId", c.GetReviewById)
+	e.PUT("/:reviewId", c.UpdateReviewById, canWrite...)
+	e.DELETE("/:reviewId", c.DeleteReviewById, canWrite...)
+	e.POST("/:reviewId/helpful", c.VoteHelpful, canWrite...)
+	e.DELETE("/:reviewId/helpful", c.RemoveHelpfulVote, canWrite...)
+}
@@ -0,0 +1,245 @@
+package review
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CreateReview(principal *jwtUtil.Principal, bookId int, input *dto.NewReview) (*dto.ReviewResponse, *response.ErrorResponse)
+	GetReviews(bookId int, input *dto.ReviewListRequest) (*dto.ReviewList, *response.ErrorResponse)
+	GetReviewById(bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse)
+	UpdateReview(principal *jwtUtil.Principal, bookId, id int, input *dto.NewReview) (*dto.ReviewResponse, *response.ErrorResponse)
+	DeleteReview(principal *jwtUtil.Principal, bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse)
+	VoteHelpful(principal *jwtUtil.Principal, bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse)
+	RemoveHelpfulVote(principal *jwtUtil.Principal, bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	BookRepository   repository.BookRepositoryInterface
+	ReviewRepository repository.ReviewRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		BookRepository:   f.BookRepository,
+		ReviewRepository: f.ReviewRepository,
+	}
+}
+
+func (u *usecase) CreateReview(principal *jwtUtil.Principal, bookId int, input *dto.NewReview) (*dto.ReviewResponse, *response.ErrorResponse) {
+	var result *dto.ReviewResponse
+
+	if errs := u.checkBook(bookId); errs != nil {
+		return result, errs
+	}
+
+	_, err := u.ReviewRepository.GetUserReview(bookId, principal.UserID)
+	if err == nil {
+		return result, response.NewErrorResponse(http.StatusConflict, constant.REVIEW_EXISTS)
+	}
+	if err != constant.RECORD_NOT_FOUND {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	review, err := u.ReviewRepository.CreateReview(&model.Review{
+		BookID: uint(bookId),
+		UserID: principal.UserID,
+		Rating: input.Rating,
+		Title:  strings.TrimSpace(input.Title),
+		Body:   strings.TrimSpace(input.Body),
+	})
+	if err != nil {
+		if err == constant.REVIEW_EXISTS {
+			return result, response.NewErrorResponse(http.StatusConflict, err)
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newReviewResponse(review), nil
+}
+
+func (u *usecase) GetReviews(bookId int, input *dto.ReviewListRequest) (*dto.ReviewList, *response.ErrorResponse) {
+	var result *dto.ReviewList
+
+	if errs := u.checkBook(bookId); errs != nil {
+		return result, errs
+	}
+
+	offset, limit := input.Window()
+	reviews, total, err := u.ReviewRepository.GetReviews(bookId, input.Sort, offset, limit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.ReviewList{
+		Reviews: []*dto.ReviewResponse{},
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+	}
+	for i := range reviews {
+		result.Reviews = append(result.Reviews, newReviewResponse(&reviews[i]))
+	}
+
+	return result, nil
+}
+
+func (u *usecase) GetReviewById(bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse) {
+	var result *dto.ReviewResponse
+
+	review, errs := u.getReview(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	return newReviewResponse(review), nil
+}
+
+// UpdateReview is only allowed to the reviewer.
+func (u *usecase) UpdateReview(principal *jwtUtil.Principal, bookId, id int, input *dto.NewReview) (*dto.ReviewResponse, *response.ErrorResponse) {
+	var result *dto.ReviewResponse
+
+	review, errs := u.getReview(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	if review.UserID != principal.UserID {
+		return result, response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized"))
+	}
+
+	review.Rating = input.Rating
+	review.Title = strings.TrimSpace(input.Title)
+	review.Body = strings.TrimSpace(input.Body)
+
+	review, err := u.ReviewRepository.UpdateReview(review)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Review not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newReviewResponse(review), nil
+}
+
+// DeleteReview is allowed to the reviewer and to staff moderating reviews.
+func (u *usecase) DeleteReview(principal *jwtUtil.Principal, bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse) {
+	var result *dto.ReviewResponse
+
+	review, errs := u.getReview(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	if review.UserID != principal.UserID && !principal.IsStaff() {
+		return result, response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized"))
+	}
+
+	if err := u.ReviewRepository.DeleteReview(review); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newReviewResponse(review), nil
+}
+
+func (u *usecase) VoteHelpful(principal *jwtUtil.Principal, bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse) {
+	var result *dto.ReviewResponse
+
+	review, errs := u.getReview(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	if review.UserID == principal.UserID {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("You can't vote for your own review"))
+	}
+
+	voted, err := u.ReviewRepository.HasHelpfulVote(review, principal.UserID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if voted {
+		return result, response.NewErrorResponse(http.StatusConflict, errors.New("You already voted for this review"))
+	}
+
+	if err := u.ReviewRepository.AddHelpfulVote(review, principal.UserID); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newReviewResponse(review), nil
+}
+
+func (u *usecase) RemoveHelpfulVote(principal *jwtUtil.Principal, bookId, id int) (*dto.ReviewResponse, *response.ErrorResponse) {
+	var result *dto.ReviewResponse
+
+	review, errs := u.getReview(bookId, id)
+	if errs != nil {
+		return result, errs
+	}
+
+	voted, err := u.ReviewRepository.HasHelpfulVote(review, principal.UserID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if !voted {
+		return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Vote not found"))
+	}
+
+	if err := u.ReviewRepository.RemoveHelpfulVote(review, principal.UserID); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return newReviewResponse(review), nil
+}
+
+func (u *usecase) checkBook(bookId int) *response.ErrorResponse {
+	_, err := u.BookRepository.GetBookById(bookId)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return response.NewErrorResponse(http.StatusNotFound, errors.New("Book not found"))
+		}
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
+
+func (u *usecase) getReview(bookId, id int) (*model.Review, *response.ErrorResponse) {
+	if errs := u.checkBook(bookId); errs != nil {
+		return nil, errs
+	}
+
+	review, err := u.ReviewRepository.GetReviewById(bookId, id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, response.NewErrorResponse(http.StatusNotFound, errors.New("Review not found"))
+		}
+		return nil, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return review, nil
+}
+
+func newReviewResponse(review *model.Review) *dto.ReviewResponse {
+	return &dto.ReviewResponse{
+		ID:           int(review.ID),
+		BookID:       int(review.BookID),
+		UserID:       int(review.UserID),
+		UserName:     review.User.Name,
+		Rating:       review.Rating,
+		Title:        review.Title,
+		Body:         review.Body,
+		HelpfulCount: review.HelpfulCount,
+		CreatedAt:    review.CreatedAt,
+		UpdatedAt:    review.UpdatedAt,
+	}
+}
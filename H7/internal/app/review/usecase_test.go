@@ -0,0 +1,101 @@
+package review
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	factoryTest = factory.NewFactory()
+	usecaseTest = NewUsecase(factoryTest)
+)
+
+func TestReviewUsecaseCreateReviewBookNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.CreateReview(&jwtUtil.Principal{UserID: 1}, 404, &dto.NewReview{Rating: 5})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Book not found")
+	}
+}
+
+func TestReviewUsecaseVoteHelpfulNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.VoteHelpful(&jwtUtil.Principal{UserID: 1}, 404, 404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Book not found")
+	}
+}
+
+func TestReviewUsecaseRatingTotals(t *testing.T) {
+	asserts := assert.New(t)
+
+	book, err := factoryTest.BookRepository.CreateNewBook(&model.Book{Title: "Rating totals"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factoryTest.BookRepository.DeleteBook(book)
+
+	reviews := []*model.Review{}
+	for i, rating := range []int{5, 3, 4} {
+		review, err := factoryTest.ReviewRepository.CreateReview(&model.Review{BookID: book.ID, UserID: uint(900000 + i), Rating: rating})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reviews = append(reviews, review)
+	}
+	defer func() {
+		for _, review := range reviews {
+			factoryTest.ReviewRepository.DeleteReview(review)
+		}
+	}()
+
+	totals := func() (int, int) {
+		current, err := factoryTest.BookRepository.GetBookById(int(book.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return current.RatingCount, current.RatingSum
+	}
+	count, sum := totals()
+	asserts.Equal(3, count)
+	asserts.Equal(12, sum)
+
+	// concurrent edits of one review each replace the rating the last one left
+	var wg sync.WaitGroup
+	for rating := 1; rating <= 4; rating++ {
+		wg.Add(1)
+		go func(rating int) {
+			defer wg.Done()
+			edit := *reviews[1]
+			edit.Rating = rating
+			_, err := factoryTest.ReviewRepository.UpdateReview(&edit)
+			asserts.NoError(err)
+		}(rating)
+	}
+	wg.Wait()
+
+	// concurrent deletes of one review take its rating off once
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stale := *reviews[2]
+			asserts.NoError(factoryTest.ReviewRepository.DeleteReview(&stale))
+		}()
+	}
+	wg.Wait()
+
+	edited, err := factoryTest.ReviewRepository.GetReviewById(int(book.ID), int(reviews[1].ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, sum = totals()
+	asserts.Equal(2, count)
+	asserts.Equal(5+edited.Rating, sum)
+}
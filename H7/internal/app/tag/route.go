@@ -0,0 +1,20 @@
+package tag
+
+import (
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+func (c *controller) Route(e *echo.Group) {
+	canManage := []echo.MiddlewareFunc{
+		c.authMiddleware,
+		middleware.RequireRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_BOOKS_WRITE),
+	}
+
+	e.GET("", c.GetAllTags)
+	e.POST("", c.CreateTag, canManage...)
+	e.PUT("/:id", c.UpdateTagById, canManage...)
+	e.DELETE("/:id", c.DeleteTagById, canManage...)
+}
@@ -0,0 +1,141 @@
+package tag
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+)
+
+type UsecaseInterface interface {
+	CreateTag(input *dto.NewTag) (*dto.TagResponse, *response.ErrorResponse)
+	GetAllTags(input *dto.TagListRequest) (*dto.TagList, *response.ErrorResponse)
+	UpdateTag(id int, input *dto.NewTag) (*dto.TagResponse, *response.ErrorResponse)
+	DeleteTag(id int) (*dto.TagResponse, *response.ErrorResponse)
+}
+
+type usecase struct {
+	TagRepository repository.TagRepositoryInterface
+}
+
+func NewUsecase(f *factory.Factory) UsecaseInterface {
+	return &usecase{
+		TagRepository: f.TagRepository,
+	}
+}
+
+func (u *usecase) CreateTag(input *dto.NewTag) (*dto.TagResponse, *response.ErrorResponse) {
+	var result *dto.TagResponse
+
+	name := model.NormalizeTagName(input.Name)
+	if errs := u.checkName(name, 0); errs != nil {
+		return result, errs
+	}
+
+	tag, err := u.TagRepository.CreateTag(&model.Tag{Name: name})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.TagResponse{
+		ID:   int(tag.ID),
+		Name: tag.Name,
+	}
+	return result, nil
+}
+
+func (u *usecase) GetAllTags(input *dto.TagListRequest) (*dto.TagList, *response.ErrorResponse) {
+	var result *dto.TagList
+
+	offset, limit := input.Window()
+	tags, total, err := u.TagRepository.GetAllTags(model.NormalizeTagName(input.Prefix), offset, limit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.TagList{
+		Tags:   []*dto.TagResponse{},
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+	for _, tag := range tags {
+		result.Tags = append(result.Tags, &dto.TagResponse{
+			ID:   int(tag.ID),
+			Name: tag.Name,
+		})
+	}
+
+	return result, nil
+}
+
+func (u *usecase) UpdateTag(id int, input *dto.NewTag) (*dto.TagResponse, *response.ErrorResponse) {
+	var result *dto.TagResponse
+
+	tag, err := u.TagRepository.GetTagById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Tag not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	name := model.NormalizeTagName(input.Name)
+	if errs := u.checkName(name, tag.ID); errs != nil {
+		return result, errs
+	}
+
+	tag.Name = name
+	tag, err = u.TagRepository.UpdateTag(tag)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.TagResponse{
+		ID:   int(tag.ID),
+		Name: tag.Name,
+	}
+	return result, nil
+}
+
+func (u *usecase) DeleteTag(id int) (*dto.TagResponse, *response.ErrorResponse) {
+	var result *dto.TagResponse
+
+	tag, err := u.TagRepository.GetTagById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("Tag not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if err := u.TagRepository.DeleteTag(tag); err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.TagResponse{
+		ID:   int(tag.ID),
+		Name: tag.Name,
+	}
+	return result, nil
+}
+
+func (u *usecase) checkName(name string, exceptId uint) *response.ErrorResponse {
+	if name == "" {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Tag can't be empty"))
+	}
+
+	tag, err := u.TagRepository.GetTagByName(name)
+	if err == nil && tag.ID != exceptId {
+		return response.NewErrorResponse(http.StatusConflict, errors.New("Tag already exists"))
+	}
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package tag
+
+import (
+	"testing"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/hansandika/internal/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	usecaseTest = NewUsecase(factory.NewFactory())
+)
+
+func TestTagUsecaseCreateTagEmpty(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.CreateTag(&dto.NewTag{Name: "   "})
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Tag can't be empty")
+	}
+}
+
+func TestTagUsecaseDeleteTagNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.DeleteTag(404)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Tag not found")
+	}
+}
@@ -0,0 +1,30 @@
+package user
+
+import (
+	"log"
+	"time"
+
+	"github.com/hansandika/internal/factory"
+)
+
+// StartAnonymizer anonymizes accounts whose deletion grace period ended, once
+// right away and then on every interval, until the process exits.
+func StartAnonymizer(f *factory.Factory, interval time.Duration) {
+	u := NewUsecase(f)
+	run := func() {
+		count, err := u.AnonymizeExpiredUsers()
+		if err != nil {
+			log.Printf("anonymizing deleted accounts: %v", err)
+		}
+		if count > 0 {
+			log.Printf("anonymized %d deleted accounts", count)
+		}
+	}
+
+	go func() {
+		run()
+		for range time.Tick(interval) {
+			run()
+		}
+	}()
+}
@@ -2,23 +2,27 @@ package user
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/middleware"
 	jwtUtil "github.com/hansandika/internal/pkg/util"
 	"github.com/hansandika/pkg/util/response"
 	"github.com/labstack/echo"
 )
 
 type controller struct {
-	usecase UsecaseInterface
+	usecase        UsecaseInterface
+	authMiddleware echo.MiddlewareFunc
 }
 
 func NewController(f *factory.Factory) *controller {
 	return &controller{
-		usecase: NewUsecase(f),
+		usecase:        NewUsecase(f),
+		authMiddleware: middleware.HandleAuth(f),
 	}
 }
 
@@ -36,14 +40,14 @@ func (co *controller) GetUserById(c echo.Context) error {
 		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
 	}
 
-	idHeader, err := strconv.Atoi(c.Request().Header.Get("X-Header-UserId"))
+	principal, err := jwtUtil.GetPrincipal(c)
 	if err != nil {
-		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id header")).SendErrorResponse(c)
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
 	}
 
-	err = jwtUtil.ValidateUser(idHeader, id)
+	err = jwtUtil.ValidateUser(int(principal.UserID), id)
 	if err != nil {
-		return response.NewErrorResponse(http.StatusUnauthorized, errors.New("This action is unauthorized")).SendErrorResponse(c)
+		return response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized")).SendErrorResponse(c)
 	}
 
 	res, errs := co.usecase.GetUserById(id)
@@ -59,14 +63,14 @@ func (co *controller) UpdateUserById(c echo.Context) error {
 		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
 	}
 
-	idHeader, err := strconv.Atoi(c.Request().Header.Get("X-Header-UserId"))
+	principal, err := jwtUtil.GetPrincipal(c)
 	if err != nil {
-		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id header")).SendErrorResponse(c)
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
 	}
 
-	err = jwtUtil.ValidateUser(idHeader, id)
+	err = jwtUtil.ValidateUser(int(principal.UserID), id)
 	if err != nil {
-		return response.NewErrorResponse(http.StatusUnauthorized, errors.New("This action is unauthorized")).SendErrorResponse(c)
+		return response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized")).SendErrorResponse(c)
 	}
 
 	var input dto.NewUser
@@ -91,19 +95,137 @@ func (co *controller) DeleteUserById(c echo.Context) error {
 		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
 	}
 
-	idHeader, err := strconv.Atoi(c.Request().Header.Get("X-Header-UserId"))
+	principal, err := jwtUtil.GetPrincipal(c)
 	if err != nil {
-		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id header")).SendErrorResponse(c)
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
 	}
 
-	err = jwtUtil.ValidateUser(idHeader, id)
+	err = jwtUtil.ValidateUser(int(principal.UserID), id)
 	if err != nil {
-		return response.NewErrorResponse(http.StatusUnauthorized, errors.New("This action is unauthorized")).SendErrorResponse(c)
+		return response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized")).SendErrorResponse(c)
 	}
 
 	res, errs := co.usecase.DeleteUser(id)
 	if errs != nil {
 		return errs.SendErrorResponse(c)
 	}
-	return response.NewSuccessResponse(http.StatusOK, "Delete user success", res).SendSuccessResponse(c)
+	return response.NewSuccessResponse(http.StatusOK, "Account deletion scheduled", res).SendSuccessResponse(c)
+}
+
+func (co *controller) RestoreUserById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	err = jwtUtil.ValidateUser(int(principal.UserID), id)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.RestoreUser(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Restore user success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) ExportUserById(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	principal, err := jwtUtil.GetPrincipal(c)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+	}
+
+	err = jwtUtil.ValidateUser(int(principal.UserID), id)
+	if err != nil {
+		return response.NewErrorResponse(http.StatusForbidden, errors.New("This action is unauthorized")).SendErrorResponse(c)
+	}
+
+	var input dto.ExportFormatRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.ExportUserData(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+
+	filename := fmt.Sprintf("user-%d-export", id)
+	if input.Format == "zip" {
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+		c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+		c.Response().WriteHeader(http.StatusOK)
+		return writeExportArchive(c.Response(), res)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename+".json"))
+	return c.JSONPretty(http.StatusOK, res, "  ")
+}
+
+func (co *controller) GrantRole(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	var input dto.RoleRequest
+	if err := c.Bind(&input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.GrantRole(id, input.Role)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Grant role success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) RevokeRole(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	input := dto.RoleRequest{Role: c.Param("role")}
+	if err := c.Validate(input); err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, err).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.RevokeRole(id, input.Role)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Revoke role success", res).SendSuccessResponse(c)
+}
+
+func (co *controller) UnlockUser(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return response.NewErrorResponse(http.StatusBadRequest, errors.New("Invalid parsing id")).SendErrorResponse(c)
+	}
+
+	res, errs := co.usecase.UnlockUser(id)
+	if errs != nil {
+		return errs.SendErrorResponse(c)
+	}
+	return response.NewSuccessResponse(http.StatusOK, "Unlock user success", res).SendSuccessResponse(c)
 }
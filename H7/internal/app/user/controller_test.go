@@ -5,21 +5,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/hansandika/database"
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
 	"github.com/hansandika/internal/mocks"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
 	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/mailer"
 	"github.com/labstack/echo"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	db             = database.GetConnection()
-	echoMock       = mocks.EchoMock{E: echo.New()}
-	f              = factory.Factory{UserRepository: repository.InitUserRepository(db)}
+	db       = database.GetConnection()
+	echoMock = mocks.EchoMock{E: echo.New()}
+	f        = factory.Factory{
+		UserRepository:               repository.InitUserRepository(db),
+		LoginThrottleRepository:      repository.InitLoginThrottleRepository(db),
+		SessionRepository:            repository.InitSessionRepository(db),
+		RefreshTokenRepository:       repository.InitRefreshTokenRepository(db),
+		PasswordResetTokenRepository: repository.InitPasswordResetTokenRepository(db),
+		RecoveryCodeRepository:       repository.InitRecoveryCodeRepository(db),
+		APIKeyRepository:             repository.InitAPIKeyRepository(db),
+		HoldRepository:               repository.InitHoldRepository(db),
+		LoanRepository:               repository.InitLoanRepository(db),
+		FineRepository:               repository.InitFineRepository(db),
+		ReviewRepository:             repository.InitReviewRepository(db),
+		Mailer:                       mailer.NewOutboxMailer(os.TempDir(), "no-reply@localhost"),
+	}
 	controllerTest = NewController(&f)
 )
 
@@ -46,7 +62,7 @@ func TestControllerUserGetUserByIdSuccess(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("2")
 
-	c.Request().Header.Add("X-Header-UserId", "2")
+	jwtUtil.SetPrincipal(c, &jwtUtil.Principal{UserID: 2})
 
 	asserts := assert.New(t)
 	// testing
@@ -66,12 +82,12 @@ func TestControllerUserGetUserByIdUnAuthorized(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("2")
 
-	c.Request().Header.Add("X-Header-UserId", "3")
+	jwtUtil.SetPrincipal(c, &jwtUtil.Principal{UserID: 3})
 
 	asserts := assert.New(t)
 	// testing
 	if asserts.NoError(controllerTest.GetUserById(c)) {
-		asserts.Equal(401, rec.Code)
+		asserts.Equal(403, rec.Code)
 
 		body := rec.Body.String()
 		asserts.Contains(body, "This action is unauthorized")
@@ -95,7 +111,7 @@ func TestControllerUserUpdateUserSuccess(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("2")
 
-	c.Request().Header.Add("X-Header-UserId", "2")
+	jwtUtil.SetPrincipal(c, &jwtUtil.Principal{UserID: 2})
 	c.Request().Header.Add("Content-Type", "application/json")
 
 	// testing
@@ -128,13 +144,13 @@ func TestControllerUserUpdateUserUnauthorized(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("2")
 
-	c.Request().Header.Add("X-Header-UserId", "4")
+	jwtUtil.SetPrincipal(c, &jwtUtil.Principal{UserID: 4})
 	c.Request().Header.Add("Content-Type", "application/json")
 
 	// testing
 	asserts := assert.New(t)
 	if asserts.NoError(controllerTest.UpdateUserById(c)) {
-		asserts.Equal(401, rec.Code)
+		asserts.Equal(403, rec.Code)
 
 		body := rec.Body.String()
 		asserts.Contains(body, "This action is unauthorized")
@@ -147,7 +163,7 @@ func TestControllerUserDeleteUserSuccess(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("8")
 
-	c.Request().Header.Add("X-Header-UserId", "8")
+	jwtUtil.SetPrincipal(c, &jwtUtil.Principal{UserID: 8})
 
 	// testing
 	asserts := assert.New(t)
@@ -155,7 +171,7 @@ func TestControllerUserDeleteUserSuccess(t *testing.T) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
-		asserts.Contains(body, "Delete user success")
+		asserts.Contains(body, "Account deletion scheduled")
 	}
 }
 
@@ -165,14 +181,32 @@ func TestControllerUserDeleteUnauthorized(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("8")
 
-	c.Request().Header.Add("X-Header-UserId", "9")
+	jwtUtil.SetPrincipal(c, &jwtUtil.Principal{UserID: 9})
 
 	// testing
 	asserts := assert.New(t)
 	if asserts.NoError(controllerTest.DeleteUserById(c)) {
-		asserts.Equal(401, rec.Code)
+		asserts.Equal(403, rec.Code)
 
 		body := rec.Body.String()
 		asserts.Contains(body, "This action is unauthorized")
 	}
 }
+
+func TestControllerUserGetUserByIdIgnoresHeader(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.SetPath("/api/v1/users/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+
+	c.Request().Header.Add("X-Header-UserId", "2")
+
+	asserts := assert.New(t)
+	// testing
+	if asserts.NoError(controllerTest.GetUserById(c)) {
+		asserts.Equal(401, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Unauthenticated")
+	}
+}
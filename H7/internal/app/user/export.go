@@ -0,0 +1,51 @@
+package user
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+
+	"github.com/hansandika/internal/dto"
+)
+
+// exportFines is fines.json, the balance next to the entries it sums up.
+type exportFines struct {
+	FineBalance int64                   `json:"fine_balance"`
+	FineEntries []dto.FineEntryResponse `json:"fine_entries"`
+}
+
+// writeExportArchive writes the export as a zip archive with one JSON document
+// per kind of record, dated ExportedAt.
+func writeExportArchive(w io.Writer, data *dto.UserDataExport) error {
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"account.json", data.Account},
+		{"sessions.json", data.Sessions},
+		{"api_keys.json", data.APIKeys},
+		{"loans.json", data.Loans},
+		{"holds.json", data.Holds},
+		{"fines.json", exportFines{FineBalance: data.FineBalance, FineEntries: data.FineEntries}},
+		{"reviews.json", data.Reviews},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		fw, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: data.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(fw)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
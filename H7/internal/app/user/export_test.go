@@ -0,0 +1,83 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hansandika/internal/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteExportArchiveHoldsEveryField(t *testing.T) {
+	asserts := assert.New(t)
+	data := &dto.UserDataExport{
+		ExportedAt:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Account:     dto.AccountExport{ID: 1, Name: "William", Email: "william@gmail.com", Roles: []string{"member"}},
+		Sessions:    []dto.SessionResponse{{ID: 2, UserAgent: "go-test", IP: "127.0.0.1"}},
+		APIKeys:     []dto.APIKeyResponse{{ID: 3, Name: "cli", Prefix: "lib_", Scopes: []string{"loans:read"}}},
+		Loans:       []dto.LoanResponse{{ID: 4, UserID: 1, BookID: 5, BookTitle: "Dune"}},
+		Holds:       []dto.HoldResponse{{ID: 6, UserID: 1, BookID: 7, BookTitle: "Emma", Status: "waiting"}},
+		FineBalance: 125,
+		FineEntries: []dto.FineEntryResponse{{ID: 8, UserID: 1, Type: "overdue", Amount: 125}},
+		Reviews:     []dto.ReviewResponse{{ID: 9, BookID: 5, UserID: 1, Rating: 4}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeExportArchive(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every top level value of the archive by its JSON name, a file holding
+	// an object contributes each of its keys
+	values := map[string]json.RawMessage{}
+	for _, file := range archive.File {
+		asserts.True(file.Modified.Equal(data.ExportedAt), file.Name)
+
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		name := strings.TrimSuffix(file.Name, ".json")
+		values[name] = content
+		var object map[string]json.RawMessage
+		if json.Unmarshal(content, &object) == nil {
+			for key, value := range object {
+				values[key] = value
+			}
+		}
+	}
+
+	fields := reflect.TypeOf(*data)
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "exported_at" {
+			continue
+		}
+
+		raw, ok := values[tag]
+		if !asserts.True(ok, "%s missing from the archive", tag) {
+			continue
+		}
+		decoded := reflect.New(field.Type)
+		if asserts.NoError(json.Unmarshal(raw, decoded.Interface()), tag) {
+			asserts.Equal(reflect.ValueOf(*data).Field(i).Interface(), decoded.Elem().Interface(), tag)
+		}
+	}
+}
@@ -1,21 +1,27 @@
 package user
 
 import (
-	"os"
-
-	jwtMiddleware "github.com/hansandika/internal/middleware"
+	"github.com/hansandika/internal/middleware"
+	"github.com/hansandika/pkg/constant"
 	"github.com/labstack/echo"
-	"github.com/labstack/echo/middleware"
 )
 
 func (c *controller) Route(e *echo.Group) {
+	isAdmin := []echo.MiddlewareFunc{
+		middleware.RequireRole(constant.ROLE_ADMIN),
+		middleware.RequireScope(constant.SCOPE_USERS_ADMIN),
+	}
 
-	e.Use(middleware.JWT([]byte(os.Getenv("JWT_SECRET"))))
-	e.GET("", c.GetAllUsers)
+	e.Use(c.authMiddleware)
+	e.GET("", c.GetAllUsers, isAdmin...)
+	e.POST("/:id/roles", c.GrantRole, isAdmin...)
+	e.DELETE("/:id/roles/:role", c.RevokeRole, isAdmin...)
+	e.DELETE("/:id/lock", c.UnlockUser, isAdmin...)
 
 	r := e.Group("/jwt")
-	r.Use(jwtMiddleware.HandleAuthJwt)
-	r.GET("/:id", c.GetUserById)
-	r.PUT("/:id", c.UpdateUserById)
-	r.DELETE("/:id", c.DeleteUserById)
+	r.GET("/:id", c.GetUserById, middleware.RequireScope(constant.SCOPE_USERS_READ))
+	r.PUT("/:id", c.UpdateUserById, middleware.RequireScope(constant.SCOPE_USERS_WRITE))
+	r.DELETE("/:id", c.DeleteUserById, middleware.RequireScope(constant.SCOPE_USERS_WRITE))
+	r.POST("/:id/restore", c.RestoreUserById, middleware.RequireScope(constant.SCOPE_USERS_WRITE))
+	r.GET("/:id/export", c.ExportUserById, middleware.RequireScope(constant.SCOPE_USERS_READ))
 }
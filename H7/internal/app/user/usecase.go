@@ -2,18 +2,35 @@ package user
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/hansandika/internal/app/auth"
+	"github.com/hansandika/internal/app/hold"
 	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
+	"github.com/hansandika/internal/model"
 	"github.com/hansandika/internal/repository"
 	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/mailer"
 	"github.com/hansandika/pkg/util"
 	"github.com/hansandika/pkg/util/response"
 )
 
 type usecase struct {
-	UserRepository repository.UserRepositoryInterface
+	UserRepository               repository.UserRepositoryInterface
+	LoginThrottleRepository      repository.LoginThrottleRepositoryInterface
+	SessionRepository            repository.SessionRepositoryInterface
+	RefreshTokenRepository       repository.RefreshTokenRepositoryInterface
+	PasswordResetTokenRepository repository.PasswordResetTokenRepositoryInterface
+	RecoveryCodeRepository       repository.RecoveryCodeRepositoryInterface
+	APIKeyRepository             repository.APIKeyRepositoryInterface
+	HoldRepository               repository.HoldRepositoryInterface
+	LoanRepository               repository.LoanRepositoryInterface
+	FineRepository               repository.FineRepositoryInterface
+	ReviewRepository             repository.ReviewRepositoryInterface
+	Mailer                       mailer.Mailer
 }
 
 type UsecaseInterface interface {
@@ -21,11 +38,29 @@ type UsecaseInterface interface {
 	GetAllUsers() ([]*dto.UserResponse, *response.ErrorResponse)
 	UpdateUser(id int, input *dto.NewUser) (*dto.UserResponse, *response.ErrorResponse)
 	DeleteUser(id int) (*dto.UserResponse, *response.ErrorResponse)
+	GrantRole(id int, role string) (*dto.UserResponse, *response.ErrorResponse)
+	RevokeRole(id int, role string) (*dto.UserResponse, *response.ErrorResponse)
+	BootstrapAdmin(input *dto.NewUser) (*dto.UserResponse, *response.ErrorResponse)
+	UnlockUser(id int) (*dto.UserResponse, *response.ErrorResponse)
+	RestoreUser(id int) (*dto.UserResponse, *response.ErrorResponse)
+	ExportUserData(id int) (*dto.UserDataExport, *response.ErrorResponse)
+	AnonymizeExpiredUsers() (int, error)
 }
 
 func NewUsecase(f *factory.Factory) UsecaseInterface {
 	return &usecase{
-		UserRepository: f.UserRepository,
+		UserRepository:               f.UserRepository,
+		LoginThrottleRepository:      f.LoginThrottleRepository,
+		SessionRepository:            f.SessionRepository,
+		RefreshTokenRepository:       f.RefreshTokenRepository,
+		PasswordResetTokenRepository: f.PasswordResetTokenRepository,
+		RecoveryCodeRepository:       f.RecoveryCodeRepository,
+		APIKeyRepository:             f.APIKeyRepository,
+		HoldRepository:               f.HoldRepository,
+		LoanRepository:               f.LoanRepository,
+		FineRepository:               f.FineRepository,
+		ReviewRepository:             f.ReviewRepository,
+		Mailer:                       f.Mailer,
 	}
 }
 
@@ -41,9 +76,12 @@ func (u *usecase) GetUserById(id int) (*dto.UserResponse, *response.ErrorRespons
 	}
 
 	result = &dto.UserResponse{
-		ID:    int(data.ID),
-		Name:  data.Name,
-		Email: data.Email,
+		ID:                  int(data.ID),
+		Name:                data.Name,
+		Email:               data.Email,
+		EmailVerified:       data.EmailVerifiedAt != nil,
+		Roles:               data.RoleList(),
+		DeletionScheduledAt: data.DeletionScheduledAt,
 	}
 
 	return result, nil
@@ -59,9 +97,11 @@ func (u *usecase) GetAllUsers() ([]*dto.UserResponse, *response.ErrorResponse) {
 
 	for _, user := range data {
 		result = append(result, &dto.UserResponse{
-			ID:    int(user.ID),
-			Name:  user.Name,
-			Email: user.Email,
+			ID:            int(user.ID),
+			Name:          user.Name,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerifiedAt != nil,
+			Roles:         user.RoleList(),
 		})
 	}
 
@@ -83,11 +123,16 @@ func (u *usecase) UpdateUser(id int, input *dto.NewUser) (*dto.UserResponse, *re
 		user.Name = input.Name
 	}
 
-	if input.Email != "" {
+	emailChanged := input.Email != "" && input.Email != user.Email
+	if emailChanged {
 		user.Email = input.Email
+		user.EmailVerifiedAt = nil
 	}
 
 	if input.Password != "" {
+		if err := util.ValidatePassword(input.Password, user.Email); err != nil {
+			return result, response.NewErrorResponse(http.StatusBadRequest, err)
+		}
 		hashedPassword, err := util.HashPassword(input.Password)
 		if err != nil {
 			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
@@ -100,15 +145,26 @@ func (u *usecase) UpdateUser(id int, input *dto.NewUser) (*dto.UserResponse, *re
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
+	if emailChanged {
+		// a failed delivery is not fatal, the user can ask for the link again
+		_ = auth.SendVerificationEmail(u.Mailer, u.UserRepository, data)
+	}
+
 	result = &dto.UserResponse{
-		ID:    int(data.ID),
-		Name:  data.Name,
-		Email: data.Email,
+		ID:            int(data.ID),
+		Name:          data.Name,
+		Email:         data.Email,
+		EmailVerified: data.EmailVerifiedAt != nil,
+		Roles:         data.RoleList(),
 	}
 
 	return result, nil
 }
 
+// DeleteUser schedules the account for deletion. The user is logged out
+// everywhere and can restore the account until the grace period ends, after
+// which AnonymizeExpiredUsers erases its personal data. Books on loan have to
+// be returned and fines paid first, they would be left on an anonymous user.
 func (u *usecase) DeleteUser(id int) (*dto.UserResponse, *response.ErrorResponse) {
 	var result *dto.UserResponse
 
@@ -120,15 +176,445 @@ func (u *usecase) DeleteUser(id int) (*dto.UserResponse, *response.ErrorResponse
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
-	err = u.UserRepository.DeleteUser(user)
+	if user.DeletionScheduledAt == nil {
+		if user.HasRole(constant.ROLE_ADMIN) {
+			count, err := u.UserRepository.CountUsersByRole(constant.ROLE_ADMIN)
+			if err != nil {
+				return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+			}
+			if count <= 1 {
+				return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Can't delete the last admin"))
+			}
+		}
+
+		loans, err := u.LoanRepository.CountActiveLoans(user.ID, 0)
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		if loans > 0 {
+			return result, response.NewErrorResponse(http.StatusConflict, errors.New("User still has books on loan"))
+		}
+
+		balance, err := u.FineRepository.GetBalance(user.ID)
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		if balance > 0 {
+			return result, response.NewErrorResponse(http.StatusConflict, errors.New("User has unpaid fines"))
+		}
+
+		scheduledAt := time.Now().Add(util.GetenvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour))
+		user.DeletionScheduledAt = &scheduledAt
+		if _, err := u.UserRepository.UpdateUser(user); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+
+		if err := u.SessionRepository.RevokeUserSessions(user.ID); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		if err := u.RefreshTokenRepository.RevokeUserRefreshTokens(user.ID); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		if err := u.APIKeyRepository.RevokeUserAPIKeys(user.ID); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		// held copies go to the next member in the queue right away
+		now := time.Now()
+		if err := u.HoldRepository.CancelUserHolds(user.ID, now, now.Add(hold.PickupWindow())); err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	result = &dto.UserResponse{
+		ID:                  int(user.ID),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerifiedAt != nil,
+		Roles:               user.RoleList(),
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+	return result, nil
+}
+
+func (u *usecase) RestoreUser(id int) (*dto.UserResponse, *response.ErrorResponse) {
+	var result *dto.UserResponse
+
+	user, err := u.UserRepository.GetUserById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Account is not scheduled for deletion"))
+	}
+
+	user.DeletionScheduledAt = nil
+	data, err := u.UserRepository.UpdateUser(user)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.UserResponse{
+		ID:            int(data.ID),
+		Name:          data.Name,
+		Email:         data.Email,
+		EmailVerified: data.EmailVerifiedAt != nil,
+		Roles:         data.RoleList(),
+	}
+	return result, nil
+}
+
+func (u *usecase) ExportUserData(id int) (*dto.UserDataExport, *response.ErrorResponse) {
+	var result *dto.UserDataExport
+
+	user, err := u.UserRepository.GetUserById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	sessions, err := u.SessionRepository.GetUserSessionHistory(user.ID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	keys, err := u.APIKeyRepository.GetUserAPIKeys(user.ID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	loans, _, err := u.LoanRepository.GetLoans(&repository.LoanFilter{UserID: user.ID, Limit: repository.NoLimit})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	holds, _, err := u.HoldRepository.GetHolds(&repository.HoldFilter{UserID: user.ID, Limit: repository.NoLimit})
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	balance, err := u.FineRepository.GetBalance(user.ID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	entries, _, err := u.FineRepository.GetFineEntries(user.ID, "", 0, repository.NoLimit)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	reviews, err := u.ReviewRepository.GetUserReviews(user.ID)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.UserDataExport{
+		ExportedAt: time.Now(),
+		Account: dto.AccountExport{
+			ID:                  int(user.ID),
+			Name:                user.Name,
+			Email:               user.Email,
+			Roles:               user.RoleList(),
+			EmailVerifiedAt:     user.EmailVerifiedAt,
+			TOTPEnabledAt:       user.TOTPEnabledAt,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
+			DeletionScheduledAt: user.DeletionScheduledAt,
+		},
+		Sessions:    []dto.SessionResponse{},
+		APIKeys:     []dto.APIKeyResponse{},
+		Loans:       []dto.LoanResponse{},
+		Holds:       []dto.HoldResponse{},
+		FineBalance: balance,
+		FineEntries: []dto.FineEntryResponse{},
+		Reviews:     []dto.ReviewResponse{},
+	}
+
+	for _, session := range sessions {
+		result.Sessions = append(result.Sessions, dto.SessionResponse{
+			ID:         int(session.ID),
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+
+	for _, key := range keys {
+		result.APIKeys = append(result.APIKeys, dto.APIKeyResponse{
+			ID:         int(key.ID),
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     key.ScopeList(),
+			CreatedAt:  key.CreatedAt,
+			ExpiresAt:  key.ExpiresAt,
+			LastUsedAt: key.LastUsedAt,
+			LastUsedIP: key.LastUsedIP,
+			RevokedAt:  key.RevokedAt,
+		})
+	}
+
+	now := time.Now()
+	for _, loan := range loans {
+		result.Loans = append(result.Loans, dto.LoanResponse{
+			ID:         int(loan.ID),
+			UserID:     int(loan.UserID),
+			BookID:     int(loan.BookID),
+			BookTitle:  loan.Book.Title,
+			CopyID:     int(loan.BookCopyID),
+			Barcode:    loan.BookCopy.Barcode,
+			CreatedAt:  loan.CreatedAt,
+			DueAt:      loan.DueAt,
+			ReturnedAt: loan.ReturnedAt,
+			Renewals:   loan.Renewals,
+			Lost:       loan.Lost,
+			Overdue:    loan.IsOverdue(now),
+		})
+	}
+
+	for _, userHold := range holds {
+		result.Holds = append(result.Holds, dto.HoldResponse{
+			ID:        int(userHold.ID),
+			UserID:    int(userHold.UserID),
+			BookID:    int(userHold.BookID),
+			BookTitle: userHold.Book.Title,
+			Status:    userHold.Status,
+			CreatedAt: userHold.CreatedAt,
+			ExpiresAt: userHold.ExpiresAt,
+			ReadyAt:   userHold.ReadyAt,
+			PickupBy:  userHold.PickupBy,
+		})
+	}
+
+	for _, entry := range entries {
+		res := dto.FineEntryResponse{
+			ID:        int(entry.ID),
+			UserID:    int(entry.UserID),
+			Type:      entry.Type,
+			Amount:    entry.Amount,
+			Note:      entry.Note,
+			CreatedAt: entry.CreatedAt,
+		}
+		if entry.LoanID != nil {
+			loanId := int(*entry.LoanID)
+			res.LoanID = &loanId
+		}
+		if entry.RelatedEntryID != nil {
+			relatedId := int(*entry.RelatedEntryID)
+			res.RelatedEntryID = &relatedId
+		}
+		result.FineEntries = append(result.FineEntries, res)
+	}
+
+	for _, review := range reviews {
+		result.Reviews = append(result.Reviews, dto.ReviewResponse{
+			ID:           int(review.ID),
+			BookID:       int(review.BookID),
+			UserID:       int(review.UserID),
+			UserName:     review.User.Name,
+			Rating:       review.Rating,
+			Title:        review.Title,
+			Body:         review.Body,
+			HelpfulCount: review.HelpfulCount,
+			CreatedAt:    review.CreatedAt,
+			UpdatedAt:    review.UpdatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// AnonymizeExpiredUsers erases the personal data of every account whose
+// deletion grace period has ended. The user row itself is kept, soft deleted,
+// so records that reference it stay consistent.
+func (u *usecase) AnonymizeExpiredUsers() (int, error) {
+	users, err := u.UserRepository.GetUsersPendingAnonymization(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range users {
+		if err := u.anonymizeUser(&users[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(users), nil
+}
+
+func (u *usecase) anonymizeUser(user *model.User) error {
+	if err := u.SessionRepository.DeleteUserSessions(user.ID); err != nil {
+		return err
+	}
+	if err := u.APIKeyRepository.DeleteUserAPIKeys(user.ID); err != nil {
+		return err
+	}
+	if err := u.RecoveryCodeRepository.DeleteRecoveryCodes(user.ID); err != nil {
+		return err
+	}
+	if err := u.RefreshTokenRepository.RevokeUserRefreshTokens(user.ID); err != nil {
+		return err
+	}
+	if err := u.PasswordResetTokenRepository.InvalidateUserPasswordResetTokens(user.ID); err != nil {
+		return err
+	}
+	if err := u.LoginThrottleRepository.ResetLoginThrottle(model.AccountThrottleKey(user.Email)); err != nil {
+		return err
+	}
+
+	// updated last so that a failed run is picked up again on the next tick
+	now := time.Now()
+	user.Name = "Deleted user"
+	user.Email = fmt.Sprintf("deleted-%d@anonymized.invalid", user.ID)
+	user.Password = ""
+	user.Roles = constant.ROLE_MEMBER
+	user.EmailVerifiedAt = nil
+	user.VerificationSentAt = nil
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	user.AnonymizedAt = &now
+	if _, err := u.UserRepository.UpdateUser(user); err != nil {
+		return err
+	}
+	return u.UserRepository.DeleteUser(user)
+}
+
+func (u *usecase) GrantRole(id int, role string) (*dto.UserResponse, *response.ErrorResponse) {
+	var result *dto.UserResponse
+
+	user, err := u.UserRepository.GetUserById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	user.AddRole(role)
+	data, err := u.UserRepository.UpdateUser(user)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.UserResponse{
+		ID:            int(data.ID),
+		Name:          data.Name,
+		Email:         data.Email,
+		EmailVerified: data.EmailVerifiedAt != nil,
+		Roles:         data.RoleList(),
+	}
+
+	return result, nil
+}
+
+func (u *usecase) RevokeRole(id int, role string) (*dto.UserResponse, *response.ErrorResponse) {
+	var result *dto.UserResponse
+
+	if role == constant.ROLE_MEMBER {
+		return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Member role can't be revoked"))
+	}
+
+	user, err := u.UserRepository.GetUserById(id)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if role == constant.ROLE_ADMIN && user.HasRole(constant.ROLE_ADMIN) {
+		count, err := u.UserRepository.CountUsersByRole(constant.ROLE_ADMIN)
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		if count <= 1 {
+			return result, response.NewErrorResponse(http.StatusBadRequest, errors.New("Can't revoke the last admin"))
+		}
+	}
+
+	user.RemoveRole(role)
+	data, err := u.UserRepository.UpdateUser(user)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	result = &dto.UserResponse{
+		ID:            int(data.ID),
+		Name:          data.Name,
+		Email:         data.Email,
+		EmailVerified: data.EmailVerifiedAt != nil,
+		Roles:         data.RoleList(),
+	}
+
+	return result, nil
+}
+
+// BootstrapAdmin creates the first admin account, or promotes an existing
+// account with the same email. It does nothing once an admin exists.
+func (u *usecase) BootstrapAdmin(input *dto.NewUser) (*dto.UserResponse, *response.ErrorResponse) {
+	var result *dto.UserResponse
+
+	count, err := u.UserRepository.CountUsersByRole(constant.ROLE_ADMIN)
+	if err != nil {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+	if count > 0 {
+		return result, nil
+	}
+
+	user, err := u.UserRepository.GetUserByEmail(input.Email)
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if err == constant.RECORD_NOT_FOUND {
+		if err := util.ValidatePassword(input.Password, input.Email); err != nil {
+			return result, response.NewErrorResponse(http.StatusBadRequest, err)
+		}
+		hashedPassword, err := util.HashPassword(input.Password)
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+		user, err = u.UserRepository.CreateNewUser(&model.User{
+			Name:     input.Name,
+			Email:    input.Email,
+			Password: hashedPassword,
+			Roles:    constant.ROLE_MEMBER,
+		})
+		if err != nil {
+			return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	return u.GrantRole(int(user.ID), constant.ROLE_ADMIN)
+}
+
+func (u *usecase) UnlockUser(id int) (*dto.UserResponse, *response.ErrorResponse) {
+	var result *dto.UserResponse
+
+	user, err := u.UserRepository.GetUserById(id)
 	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, response.NewErrorResponse(http.StatusNotFound, errors.New("User not found"))
+		}
+		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if err := u.LoginThrottleRepository.ResetLoginThrottle(model.AccountThrottleKey(user.Email)); err != nil {
 		return result, response.NewErrorResponse(http.StatusInternalServerError, err)
 	}
 
 	result = &dto.UserResponse{
-		ID:    int(user.ID),
-		Name:  user.Name,
-		Email: user.Email,
+		ID:            int(user.ID),
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Roles:         user.RoleList(),
 	}
 	return result, nil
 }
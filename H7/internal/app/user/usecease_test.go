@@ -88,3 +88,37 @@ func TestUsecaseDeleteUserByIdNotFound(t *testing.T) {
 		asserts.Equal(err.ErrorMessage.Error(), "User not found")
 	}
 }
+
+func TestUsecaseGrantRoleNotFound(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.GrantRole(404, "librarian")
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "User not found")
+	}
+}
+
+func TestUsecaseRevokeMemberRoleFailed(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.RevokeRole(2, "member")
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Member role can't be revoked")
+	}
+}
+
+func TestUsecaseRestoreUserNotScheduled(t *testing.T) {
+	asserts := assert.New(t)
+	_, err := usecaseTest.RestoreUser(2)
+	if asserts.Error(err.ErrorMessage) {
+		asserts.Equal(err.ErrorMessage.Error(), "Account is not scheduled for deletion")
+	}
+}
+
+func TestUsecaseExportUserDataSuccess(t *testing.T) {
+	asserts := assert.New(t)
+	res, err := usecaseTest.ExportUserData(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(2, res.Account.ID)
+	asserts.NotNil(res.Sessions)
+}
@@ -0,0 +1,33 @@
+package dto
+
+type NewAuthor struct {
+	Name      string `json:"name" validate:"required"`
+	Biography string `json:"biography"`
+	BirthYear *int   `json:"birth_year" validate:"omitempty,min=0"`
+	DeathYear *int   `json:"death_year" validate:"omitempty,min=0"`
+}
+
+type AuthorResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Biography string `json:"biography"`
+	BirthYear *int   `json:"birth_year"`
+	DeathYear *int   `json:"death_year"`
+}
+
+type AuthorSummary struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type AuthorListRequest struct {
+	PageRequest
+	Name string `query:"name"`
+}
+
+type AuthorList struct {
+	Authors []*AuthorResponse
+	Total   int64
+	Offset  int
+	Limit   int
+}
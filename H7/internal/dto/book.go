@@ -1,16 +1,88 @@
 package dto
 
+// NewBook links the book to the authors in AuthorIDs. Author is still
+// accepted on its own and is matched to an author record by name. On update,
+// CategoryIDs and Tags are left alone when omitted and cleared when empty.
+// ISBN takes either form, the other one is derived from it.
 type NewBook struct {
-	Title         string `json:"title" validate:"required"`
-	Description   string `json:"description" validate:"required"`
-	Author        string `json:"author" validate:"required"`
-	YearPublished int    `json:"year_published" validate:"required"`
+	Title         string   `json:"title" validate:"required"`
+	Description   string   `json:"description" validate:"required"`
+	Author        string   `json:"author" validate:"required_without=AuthorIDs"`
+	AuthorIDs     []int    `json:"author_ids" validate:"omitempty,dive,min=1"`
+	CategoryIDs   []int    `json:"category_ids" validate:"omitempty,dive,min=1"`
+	Tags          []string `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+	ISBN          string   `json:"isbn" validate:"omitempty,isbn"`
+	YearPublished int      `json:"year_published" validate:"required"`
 }
 
 type BookResponse struct {
-	ID            int    `json:"id"`
-	Title         string `json:"title"`
-	Description   string `json:"description"`
-	Author        string `json:"author"`
-	YearPublished int    `json:"year_published"`
+	ID            int               `json:"id"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Author        string            `json:"author"`
+	Authors       []AuthorSummary   `json:"authors"`
+	Categories    []CategorySummary `json:"categories"`
+	Tags          []string          `json:"tags"`
+	ISBN10        string            `json:"isbn_10,omitempty"`
+	ISBN13        string            `json:"isbn_13,omitempty"`
+	YearPublished int               `json:"year_published"`
+	Availability  BookAvailability  `json:"availability"`
+	AverageRating float64           `json:"average_rating"`
+	RatingCount   int               `json:"rating_count"`
+	Cover         *BookCover        `json:"cover,omitempty"`
+}
+
+// BookCover links the uploaded cover and its thumbnails, the URLs change
+// whenever a new cover is uploaded.
+type BookCover struct {
+	Original string `json:"original"`
+	Medium   string `json:"medium"`
+	Small    string `json:"small"`
+}
+
+type BookListRequest struct {
+	PageRequest
+	Sort       string `query:"sort" validate:"omitempty,oneof=title author year_published created_at"`
+	Order      string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Author     string `query:"author"`
+	AuthorID   int    `query:"author_id" validate:"omitempty,min=1"`
+	CategoryID int    `query:"category_id" validate:"omitempty,min=1"`
+	Tag        string `query:"tag"`
+	Title      string `query:"title"`
+	YearFrom   int    `query:"year_from" validate:"omitempty,min=0"`
+	YearTo     int    `query:"year_to" validate:"omitempty,min=0"`
+}
+
+// BookExportRequest takes the listing's filters and ordering, its paging is
+// ignored since every matching book is exported. Format defaults to json.
+type BookExportRequest struct {
+	BookListRequest
+	Format string `query:"format" validate:"omitempty,oneof=csv json ndjson"`
+}
+
+type BookList struct {
+	Books  []*BookResponse
+	Total  int64
+	Offset int
+	Limit  int
+}
+
+type BookSearchRequest struct {
+	PageRequest
+	Query string `query:"q" validate:"required"`
+}
+
+// BookSearchResponse is a ranked match. Highlights maps each matching field
+// to an HTML excerpt with the matched words wrapped in <em>.
+type BookSearchResponse struct {
+	BookResponse
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type BookSearchList struct {
+	Results []*BookSearchResponse
+	Total   int64
+	Offset  int
+	Limit   int
 }
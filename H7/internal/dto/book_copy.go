@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// NewBookCopy defaults Condition to good and Status to available. Copies are
+// only put on loan by checking them out.
+type NewBookCopy struct {
+	Barcode       string     `json:"barcode" validate:"required,max=64"`
+	AcquiredAt    *time.Time `json:"acquired_at"`
+	ShelfLocation string     `json:"shelf_location" validate:"max=64"`
+	Condition     string     `json:"condition" validate:"omitempty,oneof=new good fair poor damaged"`
+	Status        string     `json:"status" validate:"omitempty,oneof=available lost in_repair"`
+}
+
+type BookCopyResponse struct {
+	ID            int        `json:"id"`
+	BookID        int        `json:"book_id"`
+	Barcode       string     `json:"barcode"`
+	AcquiredAt    *time.Time `json:"acquired_at"`
+	ShelfLocation string     `json:"shelf_location"`
+	Condition     string     `json:"condition"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type BookCopyListRequest struct {
+	PageRequest
+	Status string `query:"status" validate:"omitempty,oneof=available on_loan on_hold lost in_repair"`
+}
+
+type BookCopyList struct {
+	Copies []*BookCopyResponse
+	Total  int64
+	Offset int
+	Limit  int
+}
+
+// BookAvailability counts a book's copies by status.
+type BookAvailability struct {
+	Total     int `json:"total"`
+	Available int `json:"available"`
+	OnLoan    int `json:"on_loan"`
+	OnHold    int `json:"on_hold"`
+	Lost      int `json:"lost"`
+	InRepair  int `json:"in_repair"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+// BookImportRequest describes an import file. Format is guessed from the
+// file name or content type when empty. Dry runs validate every row without
+// creating anything.
+type BookImportRequest struct {
+	Format string `validate:"omitempty,oneof=csv json ndjson"`
+	DryRun bool
+}
+
+// BookImportRow reports on one row, counted from 1. BookID is the created
+// book, or the existing one for duplicates.
+type BookImportRow struct {
+	Row    int      `json:"row"`
+	Status string   `json:"status"`
+	BookID int      `json:"book_id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type BookImportReport struct {
+	DryRun     bool            `json:"dry_run"`
+	Total      int             `json:"total"`
+	Created    int             `json:"created"`
+	Valid      int             `json:"valid"`
+	Invalid    int             `json:"invalid"`
+	Duplicates int             `json:"duplicates"`
+	Failed     int             `json:"failed"`
+	Rows       []BookImportRow `json:"rows"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+// NewCategory places the category below ParentID. On update a nil ParentID
+// keeps the current parent and 0 moves the category to the root.
+type NewCategory struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentID *int   `json:"parent_id" validate:"omitempty,min=0"`
+}
+
+type CategoryResponse struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id"`
+	Path     string `json:"path"`
+}
+
+// CategorySummary is a category of a book, Path reads like
+// "Fiction > Fantasy".
+type CategorySummary struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+// Fine amounts are in cents. Charges are positive, payments and waivers
+// negative.
+type FineEntryResponse struct {
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	LoanID         *int      `json:"loan_id"`
+	Type           string    `json:"type"`
+	Amount         int64     `json:"amount"`
+	Note           string    `json:"note"`
+	RelatedEntryID *int      `json:"related_entry_id"`
+	CreatedByID    *int      `json:"created_by_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// FineBalanceResponse tells whether the balance is above the threshold that
+// blocks new checkouts.
+type FineBalanceResponse struct {
+	UserID          int   `json:"user_id"`
+	Balance         int64 `json:"balance"`
+	Threshold       int64 `json:"threshold"`
+	CheckoutBlocked bool  `json:"checkout_blocked"`
+}
+
+type FineListRequest struct {
+	PageRequest
+	Type string `query:"type" validate:"omitempty,oneof=overdue_fee lost_item payment waiver adjustment"`
+}
+
+type FineList struct {
+	Entries []*FineEntryResponse
+	Total   int64
+	Offset  int
+	Limit   int
+}
+
+type NewFinePayment struct {
+	Amount int64  `json:"amount" validate:"required,min=1"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+// NewFineAdjustment charges a positive Amount and credits a negative one.
+type NewFineAdjustment struct {
+	Amount int64  `json:"amount" validate:"required"`
+	Note   string `json:"note" validate:"required,max=255"`
+}
+
+// NewFineWaiver waives the whole charge unless Amount is set.
+type NewFineWaiver struct {
+	Amount int64  `json:"amount" validate:"omitempty,min=1"`
+	Note   string `json:"note" validate:"required,max=255"`
+}
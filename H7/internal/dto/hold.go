@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// NewHold places the caller in the queue of BookID. Librarians may place a
+// hold for another member with UserID. Without ExpiresAt the hold waits for
+// HOLD_EXPIRY_DAYS.
+type NewHold struct {
+	BookID    int        `json:"book_id" validate:"required,min=1"`
+	UserID    int        `json:"user_id" validate:"omitempty,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// HoldResponse has a Position, starting at 1, while the hold is waiting.
+type HoldResponse struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	BookID    int        `json:"book_id"`
+	BookTitle string     `json:"book_title"`
+	Status    string     `json:"status"`
+	Position  int        `json:"position,omitempty"`
+	CopyID    *int       `json:"copy_id"`
+	Barcode   string     `json:"barcode,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	ReadyAt   *time.Time `json:"ready_at"`
+	PickupBy  *time.Time `json:"pickup_by"`
+}
+
+type HoldListRequest struct {
+	PageRequest
+	UserID int    `query:"user_id" validate:"omitempty,min=1"`
+	BookID int    `query:"book_id" validate:"omitempty,min=1"`
+	Status string `query:"status" validate:"omitempty,oneof=active waiting ready fulfilled cancelled expired"`
+}
+
+type HoldList struct {
+	Holds  []*HoldResponse
+	Total  int64
+	Offset int
+	Limit  int
+}
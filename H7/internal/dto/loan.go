@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// NewLoan checks out a copy of BookID for the caller. Librarians may check
+// out for another member with UserID, and pick the copy by Barcode.
+type NewLoan struct {
+	BookID  int    `json:"book_id" validate:"required,min=1"`
+	UserID  int    `json:"user_id" validate:"omitempty,min=1"`
+	Barcode string `json:"barcode" validate:"max=64"`
+}
+
+type LoanResponse struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	BookID     int        `json:"book_id"`
+	BookTitle  string     `json:"book_title"`
+	CopyID     int        `json:"copy_id"`
+	Barcode    string     `json:"barcode"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DueAt      time.Time  `json:"due_at"`
+	ReturnedAt *time.Time `json:"returned_at"`
+	Renewals   int        `json:"renewals"`
+	Lost       bool       `json:"lost"`
+	Overdue    bool       `json:"overdue"`
+}
+
+type LoanListRequest struct {
+	PageRequest
+	UserID int    `query:"user_id" validate:"omitempty,min=1"`
+	BookID int    `query:"book_id" validate:"omitempty,min=1"`
+	Status string `query:"status" validate:"omitempty,oneof=active returned overdue"`
+}
+
+type LoanList struct {
+	Loans  []*LoanResponse
+	Total  int64
+	Offset int
+	Limit  int
+}
@@ -0,0 +1,31 @@
+package dto
+
+const DefaultPerPage = 20
+
+// PageRequest accepts either page/per_page or limit/offset paging.
+type PageRequest struct {
+	Page    int `query:"page" validate:"omitempty,min=1"`
+	PerPage int `query:"per_page" validate:"omitempty,min=1,max=100"`
+	Limit   int `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset  int `query:"offset" validate:"omitempty,min=0"`
+}
+
+// Window converts the paging parameters to an offset and a limit.
+func (r *PageRequest) Window() (int, int) {
+	if r.Limit != 0 || r.Offset != 0 {
+		limit := r.Limit
+		if limit == 0 {
+			limit = DefaultPerPage
+		}
+		return r.Offset, limit
+	}
+
+	page, perPage := r.Page, r.PerPage
+	if page == 0 {
+		page = 1
+	}
+	if perPage == 0 {
+		perPage = DefaultPerPage
+	}
+	return (page - 1) * perPage, perPage
+}
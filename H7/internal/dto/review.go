@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type NewReview struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title" validate:"max=120"`
+	Body   string `json:"body" validate:"max=5000"`
+}
+
+type ReviewResponse struct {
+	ID           int       `json:"id"`
+	BookID       int       `json:"book_id"`
+	UserID       int       `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	Rating       int       `json:"rating"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	HelpfulCount int       `json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ReviewListRequest struct {
+	PageRequest
+	Sort string `query:"sort" validate:"omitempty,oneof=newest helpful"`
+}
+
+type ReviewList struct {
+	Reviews []*ReviewResponse
+	Total   int64
+	Offset  int
+	Limit   int
+}
@@ -0,0 +1,22 @@
+package dto
+
+type NewTag struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type TagResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type TagListRequest struct {
+	PageRequest
+	Prefix string `query:"prefix"`
+}
+
+type TagList struct {
+	Tags   []*TagResponse
+	Total  int64
+	Offset int
+	Limit  int
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type NewUser struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
@@ -11,13 +13,113 @@ type UserCredential struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
 type UserResponse struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles"`
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=member librarian admin"`
 }
 
 type UserResponseWithToken struct {
 	UserResponse
-	Token string `json:"token"`
+	Token                 string `json:"token,omitempty"`
+	RefreshToken          string `json:"refresh_token,omitempty"`
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" query:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SessionResponse struct {
+	ID         int       `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+type ExportFormatRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=json zip"`
+}
+
+type AccountExport struct {
+	ID                  int        `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	Roles               []string   `json:"roles"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	TOTPEnabledAt       *time.Time `json:"totp_enabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+}
+
+// UserDataExport holds everything stored about a user, as handed out by the
+// "download my data" endpoint.
+type UserDataExport struct {
+	ExportedAt  time.Time           `json:"exported_at"`
+	Account     AccountExport       `json:"account"`
+	Sessions    []SessionResponse   `json:"sessions"`
+	APIKeys     []APIKeyResponse    `json:"api_keys"`
+	Loans       []LoanResponse      `json:"loans"`
+	Holds       []HoldResponse      `json:"holds"`
+	FineBalance int64               `json:"fine_balance"`
+	FineEntries []FineEntryResponse `json:"fine_entries"`
+	Reviews     []ReviewResponse    `json:"reviews"`
 }
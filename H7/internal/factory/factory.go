@@ -3,17 +3,53 @@ package factory
 import (
 	"github.com/hansandika/database"
 	"github.com/hansandika/internal/repository"
+	"github.com/hansandika/pkg/mailer"
+	"github.com/hansandika/pkg/storage"
 )
 
 type Factory struct {
-	UserRepository repository.UserRepositoryInterface
-	BookRepository repository.BookRepositoryInterface
+	UserRepository               repository.UserRepositoryInterface
+	BookRepository               repository.BookRepositoryInterface
+	BookSearchRepository         repository.BookSearchRepositoryInterface
+	BookCopyRepository           repository.BookCopyRepositoryInterface
+	LoanRepository               repository.LoanRepositoryInterface
+	HoldRepository               repository.HoldRepositoryInterface
+	FineRepository               repository.FineRepositoryInterface
+	ReviewRepository             repository.ReviewRepositoryInterface
+	AuthorRepository             repository.AuthorRepositoryInterface
+	CategoryRepository           repository.CategoryRepositoryInterface
+	TagRepository                repository.TagRepositoryInterface
+	RefreshTokenRepository       repository.RefreshTokenRepositoryInterface
+	PasswordResetTokenRepository repository.PasswordResetTokenRepositoryInterface
+	LoginThrottleRepository      repository.LoginThrottleRepositoryInterface
+	RecoveryCodeRepository       repository.RecoveryCodeRepositoryInterface
+	APIKeyRepository             repository.APIKeyRepositoryInterface
+	SessionRepository            repository.SessionRepositoryInterface
+	Mailer                       mailer.Mailer
+	Storage                      storage.Storage
 }
 
 func NewFactory() *Factory {
 	db := database.GetConnection()
 	return &Factory{
-		UserRepository: repository.InitUserRepository(db),
-		BookRepository: repository.InitBookRepository(db),
+		UserRepository:               repository.InitUserRepository(db),
+		BookRepository:               repository.InitBookRepository(db),
+		BookSearchRepository:         repository.NewBookSearchRepository(db),
+		BookCopyRepository:           repository.InitBookCopyRepository(db),
+		LoanRepository:               repository.InitLoanRepository(db),
+		HoldRepository:               repository.InitHoldRepository(db),
+		FineRepository:               repository.InitFineRepository(db),
+		ReviewRepository:             repository.InitReviewRepository(db),
+		AuthorRepository:             repository.InitAuthorRepository(db),
+		CategoryRepository:           repository.InitCategoryRepository(db),
+		TagRepository:                repository.InitTagRepository(db),
+		RefreshTokenRepository:       repository.InitRefreshTokenRepository(db),
+		PasswordResetTokenRepository: repository.InitPasswordResetTokenRepository(db),
+		LoginThrottleRepository:      repository.InitLoginThrottleRepository(db),
+		RecoveryCodeRepository:       repository.InitRecoveryCodeRepository(db),
+		APIKeyRepository:             repository.InitAPIKeyRepository(db),
+		SessionRepository:            repository.InitSessionRepository(db),
+		Mailer:                       mailer.NewMailer(),
+		Storage:                      storage.NewStorage(),
 	}
 }
@@ -1,25 +1,56 @@
 package http
 
 import (
-	"github.com/go-playground/validator"
+	"github.com/hansandika/internal/app/apikey"
 	"github.com/hansandika/internal/app/auth"
+	"github.com/hansandika/internal/app/author"
 	"github.com/hansandika/internal/app/book"
+	"github.com/hansandika/internal/app/bookcopy"
+	"github.com/hansandika/internal/app/category"
+	"github.com/hansandika/internal/app/fine"
+	"github.com/hansandika/internal/app/hold"
+	"github.com/hansandika/internal/app/loan"
+	"github.com/hansandika/internal/app/review"
+	"github.com/hansandika/internal/app/tag"
 	"github.com/hansandika/internal/app/user"
 	"github.com/hansandika/internal/factory"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/storage"
 	"github.com/hansandika/pkg/util"
 	"github.com/labstack/echo"
 )
 
 func NewHttp(e *echo.Echo, f *factory.Factory) {
-	e.Validator = &util.CustomValidator{Validator: validator.New()}
+	e.Validator = util.NewValidator()
 
 	e.GET("/status", func(c echo.Context) error {
 		return c.JSON(200, map[string]string{"status": "OK"})
 	})
 
+	e.GET("/.well-known/jwks.json", func(c echo.Context) error {
+		ks, err := jwtUtil.GetKeySet()
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, ks.JWKS())
+	})
+
+	if storage.IsLocal() {
+		e.Static(storage.LocalPrefix, storage.LocalDir())
+	}
+
 	v1 := e.Group("/api/v1")
 
 	user.NewController(f).Route(v1.Group("/users"))
 	book.NewController(f).Route(v1.Group("/books"))
+	bookcopy.NewController(f).Route(v1.Group("/books/:id/copies"))
+	review.NewController(f).Route(v1.Group("/books/:id/reviews"))
+	author.NewController(f).Route(v1.Group("/authors"))
+	category.NewController(f).Route(v1.Group("/categories"))
+	tag.NewController(f).Route(v1.Group("/tags"))
+	loan.NewController(f).Route(v1.Group("/loans"))
+	hold.NewController(f).Route(v1.Group("/holds"))
+	fine.NewController(f).Route(v1.Group("/fines"))
 	auth.NewController(f).Route(v1.Group("/auth"))
+	apikey.NewController(f).Route(v1.Group("/api-keys"))
 }
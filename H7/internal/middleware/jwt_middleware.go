@@ -1,59 +1,108 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"errors"
-	"fmt"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/hansandika/internal/factory"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util/response"
 	"github.com/labstack/echo"
 )
 
-type jwtCustomClaims struct {
-	UserId uint `json:"user_id"`
-	jwt.StandardClaims
-}
-
-func HandleAuthJwt(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if !strings.Contains(authHeader, "Bearer") {
-			return c.JSON(http.StatusBadRequest, map[string]interface{}{
-				"message": "Invalid token",
-				"status":  http.StatusBadRequest,
-			})
-		}
-		tokenString := strings.Replace(authHeader, "Bearer ", "", -1)
+// HandleAuth verifies the credentials of the request once and stores the
+// caller as a jwtUtil.Principal on the echo context. It accepts either
+// "Authorization: Bearer <jwt>" or "Authorization: ApiKey <key>".
+func HandleAuth(f *factory.Factory) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Older clients used to send the caller id themselves; it must never be trusted.
+			c.Request().Header.Del("X-Header-UserId")
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if method, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("Signing method invalid")
-			} else if method != jwt.SigningMethodHS256 {
-				return nil, errors.New("Signing method invalid")
+			authHeader := c.Request().Header.Get("Authorization")
+			var (
+				principal *jwtUtil.Principal
+				err       error
+			)
+			switch {
+			case strings.HasPrefix(authHeader, "Bearer "):
+				principal, err = authenticateJwt(f, strings.TrimPrefix(authHeader, "Bearer "), c.RealIP())
+			case strings.HasPrefix(authHeader, "ApiKey "):
+				principal, err = authenticateAPIKey(f, strings.TrimPrefix(authHeader, "ApiKey "), c.RealIP())
+			default:
+				err = errors.New("Missing or malformed token")
 			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]interface{}{
-				"message": err.Error(),
-				"status":  http.StatusBadRequest,
-			})
+			if err != nil {
+				return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+			}
+
+			jwtUtil.SetPrincipal(c, principal)
+			return next(c)
 		}
+	}
+}
+
+func authenticateJwt(f *factory.Factory, tokenString string, ip string) (*jwtUtil.Principal, error) {
+	claims, err := jwtUtil.ParseToken(tokenString)
+	if err != nil {
+		return nil, errors.New("Invalid or expired token")
+	}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			return c.JSON(http.StatusBadRequest, map[string]interface{}{
-				"message": err.Error(),
-				"status":  http.StatusBadRequest,
-			})
+	// tokens stay valid until they expire unless their session is revoked
+	if claims.SessionId == 0 {
+		return nil, errors.New("Session has been revoked")
+	}
+	session, err := f.SessionRepository.GetSessionById(claims.SessionId)
+	if err != nil || session.UserID != claims.UserId || session.RevokedAt != nil {
+		return nil, errors.New("Session has been revoked")
+	}
+	if time.Since(session.LastSeenAt) > time.Minute || session.IP != ip {
+		if err := f.SessionRepository.TouchSession(session, ip); err != nil {
+			return nil, err
 		}
+	}
+	return jwtUtil.NewPrincipal(claims), nil
+}
 
-		userId := fmt.Sprintf("%v", claims["user_id"])
+func authenticateAPIKey(f *factory.Factory, rawKey string, ip string) (*jwtUtil.Principal, error) {
+	invalidKey := errors.New("Invalid or expired API key")
 
-		c.Request().Header.Set("X-Header-UserId", userId)
-		return next(c)
+	prefix, ok := jwtUtil.APIKeyPrefix(rawKey)
+	if !ok {
+		return nil, invalidKey
+	}
+	key, err := f.APIKeyRepository.GetAPIKeyByPrefix(prefix)
+	if err != nil {
+		return nil, invalidKey
 	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(jwtUtil.HashToken(rawKey))) != 1 {
+		return nil, invalidKey
+	}
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, invalidKey
+	}
+
+	user, err := f.UserRepository.GetUserById(int(key.UserID))
+	if err != nil {
+		return nil, invalidKey
+	}
+
+	// last use is tracked per minute, not per request
+	if key.LastUsedAt == nil || key.LastUsedIP != ip || now.Sub(*key.LastUsedAt) > time.Minute {
+		if err := f.APIKeyRepository.TouchAPIKey(key, ip); err != nil {
+			return nil, err
+		}
+	}
+
+	return &jwtUtil.Principal{
+		UserID:   user.ID,
+		Roles:    user.RoleList(),
+		MFA:      key.MFA,
+		APIKeyID: key.ID,
+		Scopes:   key.ScopeList(),
+	}, nil
 }
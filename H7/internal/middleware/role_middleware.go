@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/constant"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+// RequireRole must run after HandleAuth. The request is allowed through when
+// the caller holds at least one of the given roles.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := jwtUtil.GetPrincipal(c)
+			if err != nil {
+				return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+			}
+			if !principal.HasRole(roles...) {
+				return response.NewErrorResponse(http.StatusForbidden, errors.New("This action is forbidden")).SendErrorResponse(c)
+			}
+
+			// librarian and admin privileges are only usable from a session that passed 2FA
+			if !principal.MFA && !allowsMember(roles) {
+				return response.NewErrorResponse(http.StatusForbidden, errors.New("Two-factor authentication is required for this action")).SendErrorResponse(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+func allowsMember(roles []string) bool {
+	for _, role := range roles {
+		if role == constant.ROLE_MEMBER {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util/response"
+	"github.com/labstack/echo"
+)
+
+// RequireScope must run after HandleAuth. It only restricts API keys, see
+// jwtUtil.Principal.HasScope.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := jwtUtil.GetPrincipal(c)
+			if err != nil {
+				return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+			}
+			if !principal.HasScope(scope) {
+				return response.NewErrorResponse(http.StatusForbidden, errors.New("API key is missing the "+scope+" scope")).SendErrorResponse(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RejectAPIKeys must run after HandleAuth. It keeps account security, such as
+// second factors, sessions and the keys themselves, out of reach of API keys
+// whatever their scopes.
+func RejectAPIKeys() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := jwtUtil.GetPrincipal(c)
+			if err != nil {
+				return response.NewErrorResponse(http.StatusUnauthorized, err).SendErrorResponse(c)
+			}
+			if principal.APIKeyID != 0 {
+				return response.NewErrorResponse(http.StatusForbidden, errors.New("API keys can't be used for this action")).SendErrorResponse(c)
+			}
+			return next(c)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"io"
 	"net/http/httptest"
 
-	"github.com/go-playground/validator"
 	"github.com/hansandika/pkg/util"
 	"github.com/labstack/echo"
 )
@@ -14,7 +13,7 @@ type EchoMock struct {
 }
 
 func (em *EchoMock) RequestMock(method, path string, body io.Reader) (echo.Context, *httptest.ResponseRecorder) {
-	em.E.Validator = &util.CustomValidator{Validator: validator.New()}
+	em.E.Validator = util.NewValidator()
 	req := httptest.NewRequest(method, path, body)
 	rec := httptest.NewRecorder()
 	c := em.E.NewContext(req, rec)
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/hansandika/pkg/util"
+	"github.com/jinzhu/gorm"
+)
+
+type Author struct {
+	gorm.Model
+	Name      string `json:"name" validate:"required"`
+	NameKey   string `json:"-" gorm:"unique_index"`
+	Biography string `json:"biography" gorm:"type:text"`
+	BirthYear *int   `json:"birth_year"`
+	DeathYear *int   `json:"death_year"`
+	Books     []Book `json:"-" gorm:"many2many:book_authors;association_autoupdate:false;association_autocreate:false"`
+}
+
+// AuthorNameKey reduces a name to its letters and digits, so spellings such
+// as "J. R. R. Tolkien" and "JRR Tolkien" resolve to the same author.
+func AuthorNameKey(name string) string {
+	return strings.Join(util.Tokenize(name), "")
+}
+
+// AuthorNames joins the author names the way they are shown in Book.Author.
+func AuthorNames(authors []Author) string {
+	names := []string{}
+	for _, author := range authors {
+		names = append(names, author.Name)
+	}
+	return strings.Join(names, ", ")
+}
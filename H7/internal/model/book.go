@@ -1,13 +1,32 @@
 package model
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 )
 
+// Book keeps the joined author names in Author for display and search, the
+// authoritative links live in Authors. ISBN10 and ISBN13 are stored
+// normalized and are nil rather than empty so books without one don't collide
+// on the unique indexes. RatingCount and RatingSum total the book's reviews,
+// they are only changed together with the reviews. CoverType is the file
+// extension of the uploaded cover, empty for books without one, and
+// CoverUpdatedAt versions its URLs.
 type Book struct {
 	gorm.Model
-	Title         string `json:"title" validate:"required"`
-	Description   string `json:"description" validate:"required"`
-	Author        string `json:"author" validate:"required"`
-	YearPublished int    `json:"year_published" validate:"required"`
+	Title          string     `json:"title" validate:"required"`
+	Description    string     `json:"description" validate:"required"`
+	Author         string     `json:"author" validate:"required"`
+	Authors        []Author   `json:"authors" gorm:"many2many:book_authors;association_autoupdate:false;association_autocreate:false"`
+	Categories     []Category `json:"categories" gorm:"many2many:book_categories;association_autoupdate:false;association_autocreate:false"`
+	Tags           []Tag      `json:"tags" gorm:"many2many:book_tags;association_autoupdate:false;association_autocreate:false"`
+	Copies         []BookCopy `json:"-" gorm:"association_autoupdate:false;association_autocreate:false"`
+	ISBN10         *string    `json:"isbn_10" gorm:"column:isbn10;type:varchar(10);unique_index"`
+	ISBN13         *string    `json:"isbn_13" gorm:"column:isbn13;type:varchar(13);unique_index"`
+	YearPublished  int        `json:"year_published" validate:"required"`
+	RatingCount    int        `json:"rating_count"`
+	RatingSum      int        `json:"-"`
+	CoverType      string     `json:"-" gorm:"type:varchar(10)"`
+	CoverUpdatedAt *time.Time `json:"-"`
 }
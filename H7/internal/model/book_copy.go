@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// BookCopy is one physical item of a book. Barcodes are unique across the
+// library, deleted copies included.
+type BookCopy struct {
+	gorm.Model
+	BookID        uint       `json:"book_id" gorm:"index"`
+	Barcode       string     `json:"barcode" gorm:"type:varchar(64);unique_index"`
+	AcquiredAt    *time.Time `json:"acquired_at"`
+	ShelfLocation string     `json:"shelf_location"`
+	Condition     string     `json:"condition"`
+	Status        string     `json:"status" gorm:"index"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CategorySeparator joins the names of a category and its ancestors in
+// FullName, e.g. "Fiction > Fantasy".
+const CategorySeparator = " > "
+
+// Category is a node of the category tree. Path is the materialized path of
+// ids from the root down to the category itself, e.g. "/1/4/", so that all
+// descendants of a category share its Path as prefix.
+type Category struct {
+	gorm.Model
+	Name     string `json:"name" validate:"required"`
+	ParentID *uint  `json:"parent_id" gorm:"index"`
+	Path     string `json:"path" gorm:"index"`
+	FullName string `json:"full_name"`
+	Books    []Book `json:"-" gorm:"many2many:book_categories;association_autoupdate:false;association_autocreate:false"`
+}
+
+// SetParent places the category below parent, or at the root when parent is
+// nil. The category must already have an id.
+func (c *Category) SetParent(parent *Category) {
+	if parent == nil {
+		c.ParentID = nil
+		c.Path = fmt.Sprintf("/%d/", c.ID)
+		c.FullName = c.Name
+		return
+	}
+	c.ParentID = &parent.ID
+	c.Path = fmt.Sprintf("%s%d/", parent.Path, c.ID)
+	c.FullName = parent.FullName + CategorySeparator + c.Name
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// FineEntry is a line of a user's fines ledger. Amount is in cents, positive
+// for charges and negative for payments and waivers. A waiver points at the
+// charge it waives with RelatedEntryID, a charge can only be waived once.
+type FineEntry struct {
+	gorm.Model
+	UserID         uint   `json:"user_id" gorm:"index"`
+	LoanID         *uint  `json:"loan_id" gorm:"index"`
+	Type           string `json:"type" gorm:"index"`
+	Amount         int64  `json:"amount"`
+	Note           string `json:"note"`
+	RelatedEntryID *uint  `json:"related_entry_id" gorm:"unique_index"`
+	CreatedByID    *uint  `json:"created_by_id"`
+}
+
+func (e *FineEntry) IsCharge() bool {
+	return e.Amount > 0
+}
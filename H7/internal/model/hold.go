@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Hold is a place in the queue of a book. Holds are served in id order, a
+// waiting hold drops out of the queue at ExpiresAt and a ready hold has to be
+// picked up before PickupBy. Active is true while the hold waits or is ready
+// and NULL once it ended, so the unique index allows a single active hold per
+// member and book next to any number of ended ones.
+type Hold struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"unique_index:idx_holds_active"`
+	BookID     uint       `json:"book_id" gorm:"index;unique_index:idx_holds_active"`
+	BookCopyID *uint      `json:"book_copy_id"`
+	Book       Book       `json:"-" gorm:"association_autoupdate:false;association_autocreate:false"`
+	BookCopy   BookCopy   `json:"-" gorm:"association_autoupdate:false;association_autocreate:false"`
+	Status     string     `json:"status" gorm:"index"`
+	Active     *bool      `json:"-" gorm:"unique_index:idx_holds_active"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ReadyAt    *time.Time `json:"ready_at"`
+	PickupBy   *time.Time `json:"pickup_by"`
+}
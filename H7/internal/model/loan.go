@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Loan is open until ReturnedAt is set, Lost loans were closed because the
+// copy was lost. BookID is kept next to BookCopyID so loans can be listed per
+// book without joining the copies.
+type Loan struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"index"`
+	BookID     uint       `json:"book_id" gorm:"index"`
+	BookCopyID uint       `json:"book_copy_id" gorm:"index"`
+	Book       Book       `json:"-" gorm:"association_autoupdate:false;association_autocreate:false"`
+	BookCopy   BookCopy   `json:"-" gorm:"association_autoupdate:false;association_autocreate:false"`
+	DueAt      time.Time  `json:"due_at" gorm:"index"`
+	ReturnedAt *time.Time `json:"returned_at" gorm:"index"`
+	Renewals   int        `json:"renewals"`
+	Lost       bool       `json:"lost"`
+}
+
+func (l *Loan) IsActive() bool {
+	return l.ReturnedAt == nil
+}
+
+func (l *Loan) IsOverdue(now time.Time) bool {
+	return l.IsActive() && now.After(l.DueAt)
+}
+
+// DaysOverdue counts started days past the due date at at.
+func (l *Loan) DaysOverdue(at time.Time) int {
+	if !at.After(l.DueAt) {
+		return 0
+	}
+	late := at.Sub(l.DueAt)
+	days := int(late / (24 * time.Hour))
+	if late%(24*time.Hour) != 0 {
+		days++
+	}
+	return days
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoanDaysOverdue(t *testing.T) {
+	asserts := assert.New(t)
+	due := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+	loan := &Loan{DueAt: due}
+
+	asserts.Equal(0, loan.DaysOverdue(due.Add(-time.Hour)))
+	asserts.Equal(0, loan.DaysOverdue(due))
+	// every started day counts
+	asserts.Equal(1, loan.DaysOverdue(due.Add(time.Minute)))
+	asserts.Equal(1, loan.DaysOverdue(due.Add(24*time.Hour)))
+	asserts.Equal(2, loan.DaysOverdue(due.Add(24*time.Hour+time.Second)))
+	asserts.Equal(10, loan.DaysOverdue(due.AddDate(0, 0, 10)))
+}
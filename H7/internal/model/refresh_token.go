@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"index"`
+	SessionID uint       `json:"session_id" gorm:"index"`
+	FamilyID  string     `json:"family_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"unique_index"`
+	MFA       bool       `json:"mfa"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
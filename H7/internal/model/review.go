@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// Review is a member's rating of a book, one per member and book. Reviews and
+// their votes are deleted for good, so the member can review the book again.
+type Review struct {
+	gorm.Model
+	BookID       uint   `json:"book_id" gorm:"unique_index:idx_reviews_book_user"`
+	UserID       uint   `json:"user_id" gorm:"unique_index:idx_reviews_book_user"`
+	User         User   `json:"-" gorm:"association_autoupdate:false;association_autocreate:false"`
+	Rating       int    `json:"rating"`
+	Title        string `json:"title"`
+	Body         string `json:"body" gorm:"type:text"`
+	HelpfulCount int    `json:"helpful_count" gorm:"index"`
+}
+
+type ReviewVote struct {
+	gorm.Model
+	ReviewID uint `json:"review_id" gorm:"unique_index:idx_review_votes_review_user"`
+	UserID   uint `json:"user_id" gorm:"unique_index:idx_review_votes_review_user"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Session is created at login and referenced by the sid claim of every access
+// token and by the refresh tokens issued for it.
+type Session struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"index"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
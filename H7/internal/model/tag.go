@@ -0,0 +1,28 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+type Tag struct {
+	gorm.Model
+	Name  string `json:"name" gorm:"unique_index"`
+	Books []Book `json:"-" gorm:"many2many:book_tags;association_autoupdate:false;association_autocreate:false"`
+}
+
+// NormalizeTagName lower cases a tag and collapses its whitespace, so "Sci  Fi"
+// and "sci fi" are the same tag.
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// TagNames lists the names of tags in order.
+func TagNames(tags []Tag) []string {
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
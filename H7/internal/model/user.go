@@ -1,6 +1,10 @@
 package model
 
 import (
+	"strings"
+	"time"
+
+	"github.com/hansandika/pkg/constant"
 	"github.com/jinzhu/gorm"
 )
 
@@ -9,4 +13,62 @@ type User struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email,unique"`
 	Password string `json:"-" validate:"required"`
+	Roles    string `json:"roles" gorm:"default:'member'"`
+
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	VerificationSentAt *time.Time `json:"-"`
+
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at"`
+	TOTPLastStep  int64      `json:"-"`
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	AnonymizedAt        *time.Time `json:"-"`
+}
+
+// RequiresMFA reports whether the account holds a role that may only be used
+// after a second factor was verified.
+func (u *User) RequiresMFA() bool {
+	return u.HasRole(constant.ROLE_LIBRARIAN) || u.HasRole(constant.ROLE_ADMIN)
+}
+
+// RoleList returns the roles stored in the comma separated Roles column.
+// Every account is at least a member.
+func (u *User) RoleList() []string {
+	roles := []string{}
+	for _, role := range strings.Split(u.Roles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		roles = append(roles, constant.ROLE_MEMBER)
+	}
+	return roles
+}
+
+func (u *User) HasRole(role string) bool {
+	for _, val := range u.RoleList() {
+		if val == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *User) AddRole(role string) {
+	if u.HasRole(role) {
+		return
+	}
+	u.Roles = strings.Join(append(u.RoleList(), role), ",")
+}
+
+func (u *User) RemoveRole(role string) {
+	roles := []string{}
+	for _, val := range u.RoleList() {
+		if val != role {
+			roles = append(roles, val)
+		}
+	}
+	u.Roles = strings.Join(roles, ",")
 }
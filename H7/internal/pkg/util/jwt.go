@@ -2,53 +2,117 @@ package util
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
-type jwtCustomClaims struct {
-	UserId uint `json:"user_id"`
+const (
+	PURPOSE_EMAIL_VERIFICATION = "email_verification"
+	PURPOSE_MFA                = "mfa"
+)
+
+type JwtCustomClaims struct {
+	UserId    uint     `json:"user_id"`
+	SessionId uint     `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Purpose   string   `json:"purpose,omitempty"`
+	MFA       bool     `json:"mfa,omitempty"`
 	jwt.StandardClaims
 }
 
-func GenerateJWT(userId uint) (string, error) {
-	expirationTime := time.Now().Add(1 * time.Hour)
-	claims := &jwtCustomClaims{
-		userId,
-		jwt.StandardClaims{
+// GenerateJWT issues an access token. mfa records whether the caller proved a
+// second factor, which privileged roles require.
+func GenerateJWT(userId uint, sessionId uint, roles []string, mfa bool) (string, error) {
+	tokenId, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	expirationTime := time.Now().Add(AccessTokenTTL())
+	claims := &JwtCustomClaims{
+		UserId:    userId,
+		SessionId: sessionId,
+		Roles:     roles,
+		MFA:       mfa,
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenId,
 			ExpiresAt: expirationTime.Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	ks, err := GetKeySet()
+	if err != nil {
+		return "", err
+	}
+	tokenString, err := ks.Sign(claims)
 	if err != nil {
 		return "", err
 	}
 	return tokenString, err
 }
 
-func ValidateToken(signedToken string) error {
-	token, err := jwt.ParseWithClaims(
-		signedToken,
-		&jwtCustomClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
+// GeneratePurposeToken signs a short lived token that is only accepted by
+// ParsePurposeToken with the same purpose, never as an access token.
+func GeneratePurposeToken(userId uint, email string, purpose string, ttl time.Duration) (string, error) {
+	claims := &JwtCustomClaims{
+		UserId:  userId,
+		Email:   email,
+		Purpose: purpose,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
 		},
-	)
+	}
+	ks, err := GetKeySet()
+	if err != nil {
+		return "", err
+	}
+	return ks.Sign(claims)
+}
+
+func ParsePurposeToken(signedToken string, purpose string) (*JwtCustomClaims, error) {
+	claims, err := parseClaims(signedToken)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	claims, ok := token.Claims.(*jwtCustomClaims)
-	if !ok {
-		err = errors.New("couldn't parse claims")
-		return err
+	if claims.Purpose != purpose {
+		return nil, errors.New("token purpose invalid")
+	}
+	return claims, nil
+}
+
+func ParseToken(signedToken string) (*JwtCustomClaims, error) {
+	claims, err := parseClaims(signedToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("token purpose invalid")
+	}
+	return claims, nil
+}
+
+func parseClaims(signedToken string) (*JwtCustomClaims, error) {
+	ks, err := GetKeySet()
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.ParseWithClaims(signedToken, &JwtCustomClaims{}, ks.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*JwtCustomClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("couldn't parse claims")
 	}
 	if claims.ExpiresAt < time.Now().Local().Unix() {
-		err = errors.New("token expired")
-		return err
+		return nil, errors.New("token expired")
 	}
+	return claims, nil
+}
+
+func ValidateToken(signedToken string) error {
+	_, err := ParseToken(signedToken)
 	return err
 }
 
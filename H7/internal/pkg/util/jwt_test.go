@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTokenRejectsPurposeToken(t *testing.T) {
+	asserts := assert.New(t)
+	t.Setenv("JWT_SECRET", "secret")
+
+	signed, err := GeneratePurposeToken(1, "william@gmail.com", PURPOSE_EMAIL_VERIFICATION, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ParseToken(signed)
+	asserts.Error(err)
+
+	claims, err := ParsePurposeToken(signed, PURPOSE_EMAIL_VERIFICATION)
+	if asserts.NoError(err) {
+		asserts.Equal(uint(1), claims.UserId)
+		asserts.Equal("william@gmail.com", claims.Email)
+	}
+}
@@ -0,0 +1,206 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/hansandika/pkg/util"
+)
+
+// SigningKey is one entry of the keyset. Keys loaded from a public key PEM have
+// no PrivateKey and are only used to verify tokens issued before a rotation.
+type SigningKey struct {
+	Kid        string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds every key tokens may be verified with, and the single active
+// key new tokens are signed with.
+//
+// Keys are read from JWT_KEYS_DIR, one PEM file per key, and the file name
+// without extension is used as kid. To rotate, drop the new private key in the
+// directory and point JWT_ACTIVE_KID at it; keep the previous key (or just its
+// public half) around for at least ACCESS_TOKEN_TTL so that tokens signed
+// before the switch still verify. Without JWT_KEYS_DIR the keyset falls back to
+// HS256 with JWT_SECRET.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	secret []byte
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keySet     *KeySet
+	keySetErr  error
+	keySetOnce sync.Once
+)
+
+func GetKeySet() (*KeySet, error) {
+	keySetOnce.Do(func() {
+		keySet, keySetErr = LoadKeySet(util.Getenv("JWT_KEYS_DIR", ""), util.Getenv("JWT_ACTIVE_KID", ""))
+	})
+	return keySet, keySetErr
+}
+
+func LoadKeySet(dir string, activeKid string) (*KeySet, error) {
+	if dir == "" {
+		return &KeySet{
+			keys:   map[string]*SigningKey{},
+			secret: []byte(os.Getenv("JWT_SECRET")),
+		}, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := &KeySet{keys: map[string]*SigningKey{}}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := loadSigningKey(path, kid)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		ks.keys[kid] = key
+
+		// without an explicit kid the last private key in name order signs
+		if key.PrivateKey != nil && activeKid == "" {
+			ks.active = key
+		}
+	}
+
+	if activeKid != "" {
+		ks.active = ks.keys[activeKid]
+	}
+	if ks.active == nil || ks.active.PrivateKey == nil {
+		return nil, errors.New("no active private key in " + dir)
+	}
+	return ks, nil
+}
+
+func loadSigningKey(path string, kid string) (*SigningKey, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("invalid PEM file")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = errors.New("unsupported PEM block " + block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{Kid: kid, Method: jwt.SigningMethodRS256, PrivateKey: key, PublicKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &SigningKey{Kid: kid, Method: jwt.SigningMethodRS256, PublicKey: key}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{Kid: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: key, PublicKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{Kid: kid, Method: jwt.SigningMethodEdDSA, PublicKey: key}, nil
+	}
+	return nil, errors.New("unsupported key type, expected RSA or Ed25519")
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.Kid
+	return token.SignedString(ks.active.PrivateKey)
+}
+
+// Keyfunc resolves the verification key from the kid header and refuses any
+// algorithm other than the one the key was loaded for.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if ks.active == nil {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("signing method invalid")
+		}
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("signing method invalid")
+	}
+	return key.PublicKey, nil
+}
+
+func (ks *KeySet) JWKS() *JWKS {
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := &JWKS{Keys: []JWK{}}
+	for _, kid := range kids {
+		key := ks.keys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
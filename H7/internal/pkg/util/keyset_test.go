@@ -0,0 +1,104 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := ioutil.WriteFile(filepath.Join(dir, kid+".pem"), content, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	asserts := assert.New(t)
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "2026-01", rsaKey)
+	writePrivateKey(t, dir, "2026-02", edKey)
+
+	oldKeySet, err := LoadKeySet(dir, "2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldKeySet.Sign(&JwtCustomClaims{UserId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the newest key signs by default and tokens of the previous key still verify
+	newKeySet, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := newKeySet.Sign(&JwtCustomClaims{UserId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, signed := range []string{oldToken, newToken} {
+		token, err := jwt.ParseWithClaims(signed, &JwtCustomClaims{}, newKeySet.Keyfunc)
+		if asserts.NoError(err) {
+			asserts.True(token.Valid)
+		}
+	}
+
+	jwks := newKeySet.JWKS()
+	if asserts.Len(jwks.Keys, 2) {
+		asserts.Equal("RSA", jwks.Keys[0].Kty)
+		asserts.Equal("RS256", jwks.Keys[0].Alg)
+		asserts.Equal("OKP", jwks.Keys[1].Kty)
+		asserts.Equal("EdDSA", jwks.Keys[1].Alg)
+	}
+}
+
+func TestKeySetRejectsUnknownKid(t *testing.T) {
+	asserts := assert.New(t)
+	dir := t.TempDir()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "current", edKey)
+
+	ks, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a valid signature of the active algorithm under a kid the set doesn't know
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &JwtCustomClaims{UserId: 1})
+	token.Header["kid"] = "retired"
+	signed, err := token.SignedString(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jwt.ParseWithClaims(signed, &JwtCustomClaims{}, ks.Keyfunc)
+	if asserts.Error(err) {
+		asserts.Contains(err.Error(), "unknown signing key")
+	}
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hansandika/pkg/constant"
+	"github.com/labstack/echo"
+)
+
+const principalKey = "principal"
+
+// Principal is the authenticated caller of a request. It is only ever built by
+// the auth middleware from a verified token, never from client headers.
+type Principal struct {
+	UserID    uint
+	SessionID uint
+	Roles     []string
+	TokenID   string
+	ExpiresAt time.Time
+	MFA       bool
+
+	// set only when the caller authenticated with a personal API key
+	APIKeyID uint
+	Scopes   []string
+}
+
+func NewPrincipal(claims *JwtCustomClaims) *Principal {
+	return &Principal{
+		UserID:    claims.UserId,
+		SessionID: claims.SessionId,
+		Roles:     claims.Roles,
+		TokenID:   claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		MFA:       claims.MFA,
+	}
+}
+
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, userRole := range p.Roles {
+		for _, role := range roles {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsStaff tells whether the caller may act as librarian or admin. Like
+// RequireRole, staff privileges need a session that passed 2FA.
+func (p *Principal) IsStaff() bool {
+	return p.MFA && p.HasRole(constant.ROLE_LIBRARIAN, constant.ROLE_ADMIN)
+}
+
+// HasScope is always true for bearer tokens, API keys only carry the scopes
+// they were created with.
+func (p *Principal) HasScope(scope string) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	for _, val := range p.Scopes {
+		if val == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func SetPrincipal(c echo.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+}
+
+func GetPrincipal(c echo.Context) (*Principal, error) {
+	principal, ok := c.Get(principalKey).(*Principal)
+	if !ok || principal == nil {
+		return nil, errors.New("Unauthenticated")
+	}
+	return principal, nil
+}
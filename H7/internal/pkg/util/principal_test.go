@@ -0,0 +1,18 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/hansandika/pkg/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalIsStaff(t *testing.T) {
+	asserts := assert.New(t)
+
+	asserts.True((&Principal{Roles: []string{constant.ROLE_LIBRARIAN}, MFA: true}).IsStaff())
+	asserts.True((&Principal{Roles: []string{constant.ROLE_MEMBER, constant.ROLE_ADMIN}, MFA: true}).IsStaff())
+	// staff roles only count from a session that passed 2FA
+	asserts.False((&Principal{Roles: []string{constant.ROLE_LIBRARIAN}}).IsStaff())
+	asserts.False((&Principal{Roles: []string{constant.ROLE_MEMBER}, MFA: true}).IsStaff())
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/hansandika/pkg/util"
+)
+
+func AccessTokenTTL() time.Duration {
+	return util.GetenvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+func RefreshTokenTTL() time.Duration {
+	return util.GetenvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// GenerateRandomToken returns a url-safe random string carrying size bytes of entropy.
+func GenerateRandomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken is used for opaque tokens that are stored server-side, so a leaked
+// table cannot be replayed against the API.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+const apiKeyPrefix = "lib"
+
+// GenerateAPIKey returns a raw key of the form lib_<prefix>_<secret>. The
+// prefix is stored in clear to find the key, the whole key only as a hash.
+func GenerateAPIKey() (rawKey string, prefix string, err error) {
+	prefix, err = GenerateRandomToken(6)
+	if err != nil {
+		return "", "", err
+	}
+	prefix = strings.Replace(prefix, "_", "-", -1)
+	secret, err := GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return apiKeyPrefix + "_" + prefix + "_" + secret, prefix, nil
+}
+
+func APIKeyPrefix(rawKey string) (string, bool) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
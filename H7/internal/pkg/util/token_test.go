@@ -0,0 +1,23 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIKeyPrefix(t *testing.T) {
+	asserts := assert.New(t)
+
+	rawKey, prefix, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, ok := APIKeyPrefix(rawKey)
+	asserts.True(ok)
+	asserts.Equal(prefix, parsed)
+
+	_, ok = APIKeyPrefix("not-an-api-key")
+	asserts.False(ok)
+}
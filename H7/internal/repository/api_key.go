@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(key *model.APIKey) (*model.APIKey, error)
+	GetAPIKeyByPrefix(prefix string) (*model.APIKey, error)
+	GetUserAPIKeyById(userId uint, id int) (*model.APIKey, error)
+	GetUserAPIKeys(userId uint) ([]model.APIKey, error)
+	TouchAPIKey(key *model.APIKey, ip string) error
+	RevokeAPIKey(key *model.APIKey) error
+	RevokeUserAPIKeys(userId uint) error
+	DeleteUserAPIKeys(userId uint) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func InitAPIKeyRepository(db *gorm.DB) APIKeyRepositoryInterface {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(key *model.APIKey) (*model.APIKey, error) {
+	err := r.db.Create(&key).Error
+	return key, err
+}
+
+func (r *apiKeyRepository) GetAPIKeyByPrefix(prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Where("prefix = ?", prefix).Find(&key).Error
+	return &key, err
+}
+
+func (r *apiKeyRepository) GetUserAPIKeyById(userId uint, id int) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Where("user_id = ?", userId).Find(&key, id).Error
+	return &key, err
+}
+
+func (r *apiKeyRepository) GetUserAPIKeys(userId uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.Where("user_id = ?", userId).Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) TouchAPIKey(key *model.APIKey, ip string) error {
+	now := time.Now()
+	key.LastUsedAt = &now
+	key.LastUsedIP = ip
+	return r.db.Model(&model.APIKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ip,
+	}).Error
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(key *model.APIKey) error {
+	now := time.Now()
+	key.RevokedAt = &now
+	return r.db.Model(&model.APIKey{}).Where("id = ?", key.ID).Update("revoked_at", now).Error
+}
+
+func (r *apiKeyRepository) RevokeUserAPIKeys(userId uint) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *apiKeyRepository) DeleteUserAPIKeys(userId uint) error {
+	return r.db.Unscoped().Where("user_id = ?", userId).Delete(&model.APIKey{}).Error
+}
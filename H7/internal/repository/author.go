@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type AuthorRepositoryInterface interface {
+	CreateAuthor(author *model.Author) (*model.Author, error)
+	GetAuthorById(id int) (*model.Author, error)
+	GetAuthorsByIds(ids []int) ([]model.Author, error)
+	GetAuthorByNameKey(key string) (*model.Author, error)
+	GetAllAuthors(name string, offset, limit int) ([]model.Author, int64, error)
+	GetAuthorBooks(author *model.Author, offset, limit int) ([]model.Book, int64, error)
+	CountAuthorBooks(author *model.Author) (int64, error)
+	UpdateAuthor(author *model.Author) (*model.Author, error)
+	DeleteAuthor(author *model.Author) error
+}
+
+type authorRepository struct {
+	db *gorm.DB
+}
+
+func InitAuthorRepository(db *gorm.DB) AuthorRepositoryInterface {
+	return &authorRepository{
+		db: db,
+	}
+}
+
+func (r *authorRepository) CreateAuthor(author *model.Author) (*model.Author, error) {
+	err := r.db.Create(&author).Error
+	return author, err
+}
+
+func (r *authorRepository) GetAuthorById(id int) (*model.Author, error) {
+	var author model.Author
+	err := r.db.Find(&author, id).Error
+	return &author, err
+}
+
+func (r *authorRepository) GetAuthorsByIds(ids []int) ([]model.Author, error) {
+	var authors []model.Author
+	err := r.db.Where("id IN (?)", ids).Order("id").Find(&authors).Error
+	return authors, err
+}
+
+func (r *authorRepository) GetAuthorByNameKey(key string) (*model.Author, error) {
+	var author model.Author
+	err := r.db.Where("name_key = ?", key).Find(&author).Error
+	return &author, err
+}
+
+func (r *authorRepository) GetAllAuthors(name string, offset, limit int) ([]model.Author, int64, error) {
+	var (
+		authors []model.Author
+		total   int64
+	)
+
+	query := r.db.Model(&model.Author{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+escapeLike(name)+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return authors, total, err
+	}
+
+	err := query.Order("name, id").Offset(offset).Limit(limit).Find(&authors).Error
+	return authors, total, err
+}
+
+func (r *authorRepository) GetAuthorBooks(author *model.Author, offset, limit int) ([]model.Book, int64, error) {
+	var (
+		books []model.Book
+		total int64
+	)
+
+	query := r.db.Model(&model.Book{}).
+		Joins("JOIN book_authors ON book_authors.book_id = books.id").
+		Where("book_authors.author_id = ?", author.ID)
+	if err := query.Count(&total).Error; err != nil {
+		return books, total, err
+	}
+
+	err := preloadBook(query).Order("year_published, books.id").Offset(offset).Limit(limit).Find(&books).Error
+	return books, total, err
+}
+
+func (r *authorRepository) CountAuthorBooks(author *model.Author) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Book{}).
+		Joins("JOIN book_authors ON book_authors.book_id = books.id").
+		Where("book_authors.author_id = ?", author.ID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *authorRepository) UpdateAuthor(author *model.Author) (*model.Author, error) {
+	err := r.db.Save(&author).Error
+	return author, err
+}
+
+func (r *authorRepository) DeleteAuthor(author *model.Author) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(author).Association("Books").Clear().Error; err != nil {
+			return err
+		}
+		// name keys are unique, so a soft deleted author would block the name
+		return tx.Unscoped().Delete(author).Error
+	})
+}
@@ -1,16 +1,47 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
 	"github.com/jinzhu/gorm"
 )
 
 type BookRepositoryInterface interface {
 	CreateNewBook(book *model.Book) (*model.Book, error)
+	CreateBooks(books []*model.Book) error
 	GetBookById(id int) (*model.Book, error)
-	GetAllBooks() ([]model.Book, error)
+	GetBookByISBN(isbn13 string) (*model.Book, error)
+	GetAllBooks(filter *BookFilter) ([]model.Book, int64, error)
+	ExportBooks(filter *BookFilter, fn func(book *model.Book) error) error
 	UpdateBook(book *model.Book) (*model.Book, error)
 	DeleteBook(book *model.Book) error
+	SetBookCover(book *model.Book, coverType string, updatedAt time.Time) error
+}
+
+// BookSortColumns are the columns a book listing may be ordered by.
+var BookSortColumns = []string{"title", "author", "year_published", "created_at"}
+
+// BookFilter narrows and pages a book listing. Zero values disable a filter.
+type BookFilter struct {
+	Author        string
+	AuthorID      int
+	CategoryPath  string
+	Tag           string
+	TitleContains string
+	YearFrom      int
+	YearTo        int
+	Sort          string
+	Desc          bool
+	Offset        int
+	Limit         int
+}
+
+// preloadBook loads everything a book response shows along with the books.
+func preloadBook(db *gorm.DB) *gorm.DB {
+	return db.Preload("Authors").Preload("Categories").Preload("Tags").Preload("Copies")
 }
 
 type bookRepository struct {
@@ -23,33 +54,259 @@ func InitBookRepository(db *gorm.DB) BookRepositoryInterface {
 	}
 }
 
+// CreateNewBook inserts the book and links its Authors, Categories and Tags
+// in one transaction.
 func (r *bookRepository) CreateNewBook(book *model.Book) (*model.Book, error) {
-	err := r.db.Create(&book).Error
-	if err != nil {
+	if err := r.CreateBooks([]*model.Book{book}); err != nil {
 		return nil, err
 	}
-	book, err = r.GetBookById(int(book.ID))
-	return book, err
+	return r.GetBookById(int(book.ID))
+}
+
+// CreateBooks inserts the books and links their Authors, Categories and Tags
+// in one transaction, none of them is created when one fails.
+func (r *bookRepository) CreateBooks(books []*model.Book) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, book := range books {
+			err := saveBook(tx, book, func(tx *gorm.DB) error {
+				return tx.Create(book).Error
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// saveBook runs save with the Authors, Categories and Tags of book left out,
+// gorm would only ever add links, and then replaces the book's links with
+// them. Unsaved authors and tags are found or created in tx first, so a book
+// that fails to save leaves none of them behind. An ISBN stored concurrently
+// gives constant.ISBN_EXISTS.
+func saveBook(tx *gorm.DB, book *model.Book, save func(tx *gorm.DB) error) error {
+	authors, categories, tags := book.Authors, book.Categories, book.Tags
+	book.Authors, book.Categories, book.Tags = nil, nil, nil
+	err := save(tx)
+	book.Authors, book.Categories, book.Tags = authors, categories, tags
+	if isDuplicateKey(err) {
+		return constant.ISBN_EXISTS
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range authors {
+		if authors[i].ID != 0 {
+			continue
+		}
+		author := model.Author{NameKey: authors[i].NameKey}
+		if err := tx.Where(author).Attrs(authors[i]).FirstOrCreate(&authors[i]).Error; err != nil {
+			return err
+		}
+	}
+	for i := range tags {
+		if tags[i].ID != 0 {
+			continue
+		}
+		if err := tx.Where(model.Tag{Name: tags[i].Name}).FirstOrCreate(&tags[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(book).Association("Authors").Replace(authors).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(book).Association("Categories").Replace(categories).Error; err != nil {
+		return err
+	}
+	return tx.Model(book).Association("Tags").Replace(tags).Error
 }
 
 func (r *bookRepository) GetBookById(id int) (*model.Book, error) {
 	var book model.Book
-	err := r.db.Find(&book, id).Error
+	err := preloadBook(r.db).Find(&book, id).Error
+	return &book, err
+}
+
+func (r *bookRepository) GetBookByISBN(isbn13 string) (*model.Book, error) {
+	var book model.Book
+	err := preloadBook(r.db).Where("isbn13 = ?", isbn13).Find(&book).Error
 	return &book, err
 }
 
-func (r *bookRepository) GetAllBooks() ([]model.Book, error) {
+func (r *bookRepository) GetAllBooks(filter *BookFilter) ([]model.Book, int64, error) {
+	var (
+		books []model.Book
+		total int64
+	)
+
+	query := r.filterBooks(r.db.Model(&model.Book{}), filter)
+	if err := query.Count(&total).Error; err != nil {
+		return books, total, err
+	}
+
+	err := preloadBook(query).Order(bookOrder(filter)).Offset(filter.Offset).Limit(filter.Limit).Find(&books).Error
+	return books, total, err
+}
+
+// exportChunkSize is the number of books ExportBooks loads at a time.
+const exportChunkSize = 500
+
+// ExportBooks calls fn with every book matching filter, in listing order and
+// ignoring Offset and Limit. The matching ids are read with a rows cursor and
+// the books are loaded by chunks of ids, so only one chunk is held in memory.
+func (r *bookRepository) ExportBooks(filter *BookFilter, fn func(book *model.Book) error) error {
+	rows, err := r.filterBooks(r.db.Model(&model.Book{}), filter).Select("id").Order(bookOrder(filter)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	ids := make([]uint, 0, exportChunkSize)
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		if len(ids) == exportChunkSize {
+			if err := r.exportChunk(ids, fn); err != nil {
+				return err
+			}
+			ids = ids[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return r.exportChunk(ids, fn)
+}
+
+func (r *bookRepository) exportChunk(ids []uint, fn func(book *model.Book) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
 	var books []model.Book
-	err := r.db.Find(&books).Error
-	return books, err
+	if err := preloadBook(r.db).Where("id IN (?)", ids).Find(&books).Error; err != nil {
+		return err
+	}
+	byId := map[uint]*model.Book{}
+	for i := range books {
+		byId[books[i].ID] = &books[i]
+	}
+
+	for _, id := range ids {
+		// books deleted since the cursor read their id are skipped
+		book, ok := byId[id]
+		if !ok {
+			continue
+		}
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bookOrder(filter *BookFilter) string {
+	order := "id"
+	for _, column := range BookSortColumns {
+		if filter.Sort == column {
+			order = column
+		}
+	}
+	if filter.Desc {
+		order += " desc"
+	}
+	if order != "id" {
+		// keep pages stable when the sort column has duplicates
+		order += ", id"
+	}
+	return order
+}
+
+func (r *bookRepository) filterBooks(query *gorm.DB, filter *BookFilter) *gorm.DB {
+	if filter.Author != "" {
+		query = query.Where("author LIKE ?", "%"+escapeLike(filter.Author)+"%")
+	}
+	if filter.AuthorID != 0 {
+		query = query.Where("id IN (?)", r.db.Table("book_authors").Select("book_id").Where("author_id = ?", filter.AuthorID).SubQuery())
+	}
+	if filter.CategoryPath != "" {
+		// the category itself and every category below it
+		categories := r.db.Table("book_categories").
+			Select("book_categories.book_id").
+			Joins("JOIN categories ON categories.id = book_categories.category_id").
+			Where("categories.path LIKE ? AND categories.deleted_at IS NULL", escapeLike(filter.CategoryPath)+"%")
+		query = query.Where("id IN (?)", categories.SubQuery())
+	}
+	if filter.Tag != "" {
+		tags := r.db.Table("book_tags").
+			Select("book_tags.book_id").
+			Joins("JOIN tags ON tags.id = book_tags.tag_id").
+			Where("tags.name = ?", filter.Tag)
+		query = query.Where("id IN (?)", tags.SubQuery())
+	}
+	if filter.TitleContains != "" {
+		query = query.Where("title LIKE ?", "%"+escapeLike(filter.TitleContains)+"%")
+	}
+	if filter.YearFrom != 0 {
+		query = query.Where("year_published >= ?", filter.YearFrom)
+	}
+	if filter.YearTo != 0 {
+		query = query.Where("year_published <= ?", filter.YearTo)
+	}
+	return query
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
 }
 
+// UpdateBook saves the book and replaces its Authors, Categories and Tags
+// links in one transaction. It leaves the rating totals alone, reviews change
+// them concurrently.
 func (r *bookRepository) UpdateBook(book *model.Book) (*model.Book, error) {
-	err := r.db.Save(&book).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return saveBook(tx, book, func(tx *gorm.DB) error {
+			return tx.Omit("rating_count", "rating_sum", "cover_type", "cover_updated_at").Save(book).Error
+		})
+	})
 	return book, err
 }
 
+func (r *bookRepository) SetBookCover(book *model.Book, coverType string, updatedAt time.Time) error {
+	err := r.db.Model(book).UpdateColumns(map[string]interface{}{
+		"cover_type":       coverType,
+		"cover_updated_at": updatedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+	book.CoverType = coverType
+	book.CoverUpdatedAt = &updatedAt
+	return nil
+}
+
+// DeleteBook frees the book's ISBNs so the same edition can be added again,
+// the soft deleted row would otherwise still hold the unique indexes. The
+// book's copies are deleted and its waiting holds cancelled along with it.
 func (r *bookRepository) DeleteBook(book *model.Book) error {
-	err := r.db.Delete(&book).Error
-	return err
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", book.ID).Delete(&model.BookCopy{}).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&model.Hold{}).
+			Where("book_id = ? AND status = ?", book.ID, constant.HOLD_STATUS_WAITING).
+			UpdateColumns(endedHold(constant.HOLD_STATUS_CANCELLED)).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(book).UpdateColumns(map[string]interface{}{"isbn10": nil, "isbn13": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(book).Error
+	})
 }
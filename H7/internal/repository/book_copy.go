@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/jinzhu/gorm"
+)
+
+type BookCopyRepositoryInterface interface {
+	CreateBookCopy(bookCopy *model.BookCopy) (*model.BookCopy, error)
+	GetBookCopyById(bookId, id int) (*model.BookCopy, error)
+	GetBookCopyByBarcode(barcode string) (*model.BookCopy, error)
+	GetBookCopies(bookId int, status string, offset, limit int) ([]model.BookCopy, int64, error)
+	UpdateBookCopy(bookCopy *model.BookCopy) (*model.BookCopy, error)
+	SetBookCopyStatus(bookCopy *model.BookCopy, status string) error
+	DeleteBookCopy(bookCopy *model.BookCopy) error
+}
+
+type bookCopyRepository struct {
+	db *gorm.DB
+}
+
+func InitBookCopyRepository(db *gorm.DB) BookCopyRepositoryInterface {
+	return &bookCopyRepository{
+		db: db,
+	}
+}
+
+func (r *bookCopyRepository) CreateBookCopy(bookCopy *model.BookCopy) (*model.BookCopy, error) {
+	err := r.db.Create(&bookCopy).Error
+	return bookCopy, err
+}
+
+func (r *bookCopyRepository) GetBookCopyById(bookId, id int) (*model.BookCopy, error) {
+	var bookCopy model.BookCopy
+	err := r.db.Where("book_id = ?", bookId).Find(&bookCopy, id).Error
+	return &bookCopy, err
+}
+
+// GetBookCopyByBarcode also finds deleted copies, their barcodes stay taken.
+func (r *bookCopyRepository) GetBookCopyByBarcode(barcode string) (*model.BookCopy, error) {
+	var bookCopy model.BookCopy
+	err := r.db.Unscoped().Where("barcode = ?", barcode).Find(&bookCopy).Error
+	return &bookCopy, err
+}
+
+func (r *bookCopyRepository) GetBookCopies(bookId int, status string, offset, limit int) ([]model.BookCopy, int64, error) {
+	var (
+		copies []model.BookCopy
+		total  int64
+	)
+
+	query := r.db.Model(&model.BookCopy{}).Where("book_id = ?", bookId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return copies, total, err
+	}
+
+	err := query.Order("barcode").Offset(offset).Limit(limit).Find(&copies).Error
+	return copies, total, err
+}
+
+// UpdateBookCopy leaves the status alone, checkouts change it concurrently.
+// Use SetBookCopyStatus for that.
+func (r *bookCopyRepository) UpdateBookCopy(bookCopy *model.BookCopy) (*model.BookCopy, error) {
+	err := r.db.Omit("status").Save(&bookCopy).Error
+	return bookCopy, err
+}
+
+// SetBookCopyStatus changes the status of a copy that isn't on loan or on
+// hold. It returns constant.COPY_IN_USE when the copy was checked out or
+// assigned to a hold in the meantime.
+func (r *bookCopyRepository) SetBookCopyStatus(bookCopy *model.BookCopy, status string) error {
+	updated := r.db.Model(&model.BookCopy{}).
+		Where("id = ? AND status NOT IN (?)", bookCopy.ID, []string{constant.COPY_STATUS_ON_LOAN, constant.COPY_STATUS_ON_HOLD}).
+		UpdateColumn("status", status)
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected != 1 {
+		return constant.COPY_IN_USE
+	}
+	bookCopy.Status = status
+	return nil
+}
+
+func (r *bookCopyRepository) DeleteBookCopy(bookCopy *model.BookCopy) error {
+	return r.db.Delete(&bookCopy).Error
+}
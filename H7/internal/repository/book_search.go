@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/util"
+	"github.com/jinzhu/gorm"
+)
+
+type BookSearchHit struct {
+	model.Book
+	Score float64
+}
+
+// BookSearchRepositoryInterface ranks books against a free text query over
+// their title, description and author.
+type BookSearchRepositoryInterface interface {
+	IndexBook(book *model.Book) error
+	RemoveBook(id uint) error
+	SearchBooks(query string, offset, limit int) ([]BookSearchHit, int64, error)
+}
+
+// NewBookSearchRepository picks the implementation from SEARCH_DRIVER. The
+// memory driver is filled with the books already stored in db.
+func NewBookSearchRepository(db *gorm.DB) BookSearchRepositoryInterface {
+	switch util.Getenv("SEARCH_DRIVER", "mysql") {
+	case "memory":
+		index := InitMemoryBookSearchRepository(db)
+		var books []model.Book
+		if err := preloadBook(db).Find(&books).Error; err == nil {
+			for i := range books {
+				index.IndexBook(&books[i])
+			}
+		}
+		return index
+	default:
+		return InitMySQLBookSearchRepository(db)
+	}
+}
+
+const bookMatch = "MATCH(title, description, author) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// mysqlBookSearchRepository relies on the FULLTEXT index created by the
+// migration, which MySQL keeps up to date by itself.
+type mysqlBookSearchRepository struct {
+	db *gorm.DB
+}
+
+func InitMySQLBookSearchRepository(db *gorm.DB) BookSearchRepositoryInterface {
+	return &mysqlBookSearchRepository{
+		db: db,
+	}
+}
+
+func (r *mysqlBookSearchRepository) IndexBook(book *model.Book) error {
+	return nil
+}
+
+func (r *mysqlBookSearchRepository) RemoveBook(id uint) error {
+	return nil
+}
+
+func (r *mysqlBookSearchRepository) SearchBooks(query string, offset, limit int) ([]BookSearchHit, int64, error) {
+	var (
+		hits  []BookSearchHit
+		total int64
+	)
+
+	if err := r.db.Model(&model.Book{}).Where(bookMatch, query).Count(&total).Error; err != nil {
+		return hits, total, err
+	}
+
+	err := r.db.Model(&model.Book{}).
+		Select("books.*, "+bookMatch+" AS score", query).
+		Where(bookMatch, query).
+		Order("score desc, id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&hits).Error
+	if err != nil || len(hits) == 0 {
+		return hits, total, err
+	}
+
+	// Scan can't preload, so the associations are loaded separately
+	err = loadHitBooks(r.db, hits)
+	return hits, total, err
+}
+
+// loadHitBooks replaces the book of each hit with the stored one and its
+// associations, such as its current copies.
+func loadHitBooks(db *gorm.DB, hits []BookSearchHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	ids := []uint{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	var books []model.Book
+	if err := preloadBook(db).Where("id IN (?)", ids).Find(&books).Error; err != nil {
+		return err
+	}
+	byId := map[uint]model.Book{}
+	for _, book := range books {
+		byId[book.ID] = book
+	}
+	for i := range hits {
+		if book, ok := byId[hits[i].ID]; ok {
+			hits[i].Book = book
+		}
+	}
+	return nil
+}
+
+// matches in the title count more than in the author, and both more than in
+// the description
+var bookFieldWeights = map[string]float64{
+	"title":       3,
+	"author":      2,
+	"description": 1,
+}
+
+// memoryBookSearchRepository is an in-process inverted index, used by tests
+// and by databases without full-text support. Only the text of a book is
+// indexed, the books of a result page are read from db, when it's set, so
+// their copies and ratings are current.
+type memoryBookSearchRepository struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	books    map[uint]model.Book
+	postings map[string]map[uint]float64
+}
+
+func InitMemoryBookSearchRepository(db *gorm.DB) BookSearchRepositoryInterface {
+	return &memoryBookSearchRepository{
+		db:       db,
+		books:    map[uint]model.Book{},
+		postings: map[string]map[uint]float64{},
+	}
+}
+
+func (r *memoryBookSearchRepository) IndexBook(book *model.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(book.ID)
+	r.books[book.ID] = *book
+
+	fields := map[string]string{
+		"title":       book.Title,
+		"author":      book.Author,
+		"description": book.Description,
+	}
+	for field, text := range fields {
+		for _, token := range util.Tokenize(text) {
+			if r.postings[token] == nil {
+				r.postings[token] = map[uint]float64{}
+			}
+			r.postings[token][book.ID] += bookFieldWeights[field]
+		}
+	}
+	return nil
+}
+
+func (r *memoryBookSearchRepository) RemoveBook(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(id)
+	return nil
+}
+
+func (r *memoryBookSearchRepository) remove(id uint) {
+	if _, ok := r.books[id]; !ok {
+		return
+	}
+	delete(r.books, id)
+	for token, docs := range r.postings {
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(r.postings, token)
+		}
+	}
+}
+
+// SearchBooks scores every book by the weighted term frequency of each query
+// term, scaled by how rare the term is across the catalogue.
+func (r *memoryBookSearchRepository) SearchBooks(query string, offset, limit int) ([]BookSearchHit, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := map[uint]float64{}
+	seen := map[string]bool{}
+	for _, term := range util.Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		docs := r.postings[term]
+		idf := math.Log(1 + float64(len(r.books))/float64(len(docs)+1))
+		for id, weight := range docs {
+			scores[id] += weight * idf
+		}
+	}
+
+	hits := []BookSearchHit{}
+	for id, score := range scores {
+		hits = append(hits, BookSearchHit{Book: r.books[id], Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	total := int64(len(hits))
+	if offset > len(hits) {
+		offset = len(hits)
+	}
+	hits = hits[offset:]
+	if limit < len(hits) {
+		hits = hits[:limit]
+	}
+	if r.db == nil {
+		return hits, total, nil
+	}
+	err := loadHitBooks(r.db, hits)
+	return hits, total, err
+}
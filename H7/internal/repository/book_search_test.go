@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBookSearchRanking(t *testing.T) {
+	asserts := assert.New(t)
+	index := InitMemoryBookSearchRepository(nil)
+
+	index.IndexBook(&model.Book{Model: gorm.Model{ID: 1}, Title: "The Hobbit", Author: "J. R. R. Tolkien", Description: "A journey to the lonely mountain and back, long before the rings war"})
+	index.IndexBook(&model.Book{Model: gorm.Model{ID: 2}, Title: "The Lord of the Rings", Author: "J. R. R. Tolkien", Description: "The war of the ring"})
+	index.IndexBook(&model.Book{Model: gorm.Model{ID: 3}, Title: "Dune", Author: "Frank Herbert", Description: "Spice and sand"})
+
+	hits, total, err := index.SearchBooks("rings", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(int64(2), total)
+	asserts.Equal(uint(2), hits[0].ID)
+	asserts.Equal(uint(1), hits[1].ID)
+}
+
+func TestMemoryBookSearchUpdateAndRemove(t *testing.T) {
+	asserts := assert.New(t)
+	index := InitMemoryBookSearchRepository(nil)
+
+	book := &model.Book{Model: gorm.Model{ID: 1}, Title: "Dune", Author: "Frank Herbert"}
+	index.IndexBook(book)
+
+	book.Title = "Children of Dune"
+	index.IndexBook(book)
+	hits, _, _ := index.SearchBooks("children", 0, 10)
+	asserts.Len(hits, 1)
+
+	index.RemoveBook(book.ID)
+	_, total, _ := index.SearchBooks("dune", 0, 10)
+	asserts.Equal(int64(0), total)
+}
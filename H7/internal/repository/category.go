@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type CategoryRepositoryInterface interface {
+	CreateCategory(category *model.Category, parent *model.Category) (*model.Category, error)
+	GetCategoryById(id int) (*model.Category, error)
+	GetCategoriesByIds(ids []int) ([]model.Category, error)
+	GetAllCategories() ([]model.Category, error)
+	CategoryNameExists(parentId *uint, name string, exceptId uint) (bool, error)
+	CountChildCategories(category *model.Category) (int64, error)
+	MoveCategory(category *model.Category, parent *model.Category) (*model.Category, error)
+	DeleteCategory(category *model.Category) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func InitCategoryRepository(db *gorm.DB) CategoryRepositoryInterface {
+	return &categoryRepository{
+		db: db,
+	}
+}
+
+// CreateCategory inserts the category first, since its path ends with its own
+// id.
+func (r *categoryRepository) CreateCategory(category *model.Category, parent *model.Category) (*model.Category, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		category.SetParent(parent)
+		return tx.Save(category).Error
+	})
+	return category, err
+}
+
+func (r *categoryRepository) GetCategoryById(id int) (*model.Category, error) {
+	var category model.Category
+	err := r.db.Find(&category, id).Error
+	return &category, err
+}
+
+func (r *categoryRepository) GetCategoriesByIds(ids []int) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.Where("id IN (?)", ids).Order("id").Find(&categories).Error
+	return categories, err
+}
+
+// GetAllCategories lists the whole tree, each parent right before its
+// children.
+func (r *categoryRepository) GetAllCategories() ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.Order("full_name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) CategoryNameExists(parentId *uint, name string, exceptId uint) (bool, error) {
+	var count int64
+
+	query := r.db.Model(&model.Category{}).Where("name = ? AND id <> ?", name, exceptId)
+	if parentId == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentId)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *categoryRepository) CountChildCategories(category *model.Category) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Category{}).Where("parent_id = ?", category.ID).Count(&count).Error
+	return count, err
+}
+
+// MoveCategory saves a renamed or moved category and rewrites the path and
+// full name of all of its descendants to match.
+func (r *categoryRepository) MoveCategory(category *model.Category, parent *model.Category) (*model.Category, error) {
+	oldPath, oldFullName := category.Path, category.FullName
+	category.SetParent(parent)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+
+		var descendants []model.Category
+		if err := tx.Where("path LIKE ? AND id <> ?", escapeLike(oldPath)+"%", category.ID).Find(&descendants).Error; err != nil {
+			return err
+		}
+		for i := range descendants {
+			descendants[i].Path = category.Path + strings.TrimPrefix(descendants[i].Path, oldPath)
+			descendants[i].FullName = category.FullName + strings.TrimPrefix(descendants[i].FullName, oldFullName)
+			if err := tx.Save(&descendants[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return category, err
+}
+
+func (r *categoryRepository) DeleteCategory(category *model.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(category).Association("Books").Clear().Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
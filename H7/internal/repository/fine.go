@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type FineRepositoryInterface interface {
+	CreateFineEntry(entry *model.FineEntry) (*model.FineEntry, error)
+	GetFineEntryById(id int) (*model.FineEntry, error)
+	GetFineEntries(userId uint, entryType string, offset, limit int) ([]model.FineEntry, int64, error)
+	GetWaiver(entry *model.FineEntry) (*model.FineEntry, error)
+	GetBalance(userId uint) (int64, error)
+}
+
+type fineRepository struct {
+	db *gorm.DB
+}
+
+func InitFineRepository(db *gorm.DB) FineRepositoryInterface {
+	return &fineRepository{
+		db: db,
+	}
+}
+
+func (r *fineRepository) CreateFineEntry(entry *model.FineEntry) (*model.FineEntry, error) {
+	err := r.db.Create(&entry).Error
+	return entry, err
+}
+
+func (r *fineRepository) GetFineEntryById(id int) (*model.FineEntry, error) {
+	var entry model.FineEntry
+	err := r.db.Find(&entry, id).Error
+	return &entry, err
+}
+
+func (r *fineRepository) GetFineEntries(userId uint, entryType string, offset, limit int) ([]model.FineEntry, int64, error) {
+	var (
+		entries []model.FineEntry
+		total   int64
+	)
+
+	query := r.db.Model(&model.FineEntry{}).Where("user_id = ?", userId)
+	if entryType != "" {
+		query = query.Where("type = ?", entryType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return entries, total, err
+	}
+
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
+
+func (r *fineRepository) GetWaiver(entry *model.FineEntry) (*model.FineEntry, error) {
+	var waiver model.FineEntry
+	err := r.db.Where("related_entry_id = ?", entry.ID).Find(&waiver).Error
+	return &waiver, err
+}
+
+func (r *fineRepository) GetBalance(userId uint) (int64, error) {
+	var balance struct {
+		Total int64
+	}
+	err := r.db.Model(&model.FineEntry{}).
+		Select("COALESCE(SUM(amount), 0) AS total").
+		Where("user_id = ?", userId).
+		Scan(&balance).Error
+	return balance.Total, err
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/jinzhu/gorm"
+)
+
+type HoldRepositoryInterface interface {
+	CreateHold(hold *model.Hold) (*model.Hold, error)
+	GetHoldById(id int) (*model.Hold, error)
+	GetActiveHold(userId, bookId uint) (*model.Hold, error)
+	GetHolds(filter *HoldFilter) ([]model.Hold, int64, error)
+	CountHoldsAhead(hold *model.Hold, now time.Time) (int64, error)
+	CancelHold(hold *model.Hold, now, pickupBy time.Time) error
+	CancelUserHolds(userId uint, now, pickupBy time.Time) error
+	ExpireHolds(now, pickupBy time.Time) (int, error)
+	ReleaseCopy(bookCopy *model.BookCopy, now, pickupBy time.Time) error
+}
+
+// mysqlDuplicateEntry is the MySQL error number of a unique index violation.
+const mysqlDuplicateEntry = 1062
+
+// HoldStatusActive matches waiting and ready holds in a HoldFilter.
+const HoldStatusActive = "active"
+
+var activeHoldStatuses = []string{constant.HOLD_STATUS_WAITING, constant.HOLD_STATUS_READY}
+
+// HoldFilter narrows and pages a hold listing. Zero values disable a filter.
+type HoldFilter struct {
+	UserID uint
+	BookID uint
+	Status string
+	Offset int
+	Limit  int
+}
+
+func preloadHold(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+	return db.Preload("Book", unscoped).Preload("BookCopy", unscoped)
+}
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+func InitHoldRepository(db *gorm.DB) HoldRepositoryInterface {
+	return &holdRepository{
+		db: db,
+	}
+}
+
+// CreateHold queues hold unless a copy of its book is available, it returns
+// constant.COPY_AVAILABLE then. The book's copies stay locked until the hold
+// is in the queue, so a copy returned meanwhile goes to the hold instead of
+// the shelf. It returns constant.HOLD_EXISTS when the member already has an
+// active hold on the book, idx_holds_active enforces that for concurrent
+// requests.
+func (r *holdRepository) CreateHold(hold *model.Hold) (*model.Hold, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var copies []model.BookCopy
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("book_id = ?", hold.BookID).Find(&copies).Error; err != nil {
+			return err
+		}
+		for _, bookCopy := range copies {
+			if bookCopy.Status == constant.COPY_STATUS_AVAILABLE {
+				return constant.COPY_AVAILABLE
+			}
+		}
+
+		var count int64
+		err := tx.Model(&model.Hold{}).
+			Where("user_id = ? AND book_id = ? AND status IN (?)", hold.UserID, hold.BookID, activeHoldStatuses).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return constant.HOLD_EXISTS
+		}
+
+		active := true
+		hold.Active = &active
+		if err := tx.Create(hold).Error; err != nil {
+			if isDuplicateKey(err) {
+				return constant.HOLD_EXISTS
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetHoldById(int(hold.ID))
+}
+
+func (r *holdRepository) GetHoldById(id int) (*model.Hold, error) {
+	var hold model.Hold
+	err := preloadHold(r.db).Find(&hold, id).Error
+	return &hold, err
+}
+
+func (r *holdRepository) GetActiveHold(userId, bookId uint) (*model.Hold, error) {
+	var hold model.Hold
+	err := preloadHold(r.db).
+		Where("user_id = ? AND book_id = ? AND status IN (?)", userId, bookId, activeHoldStatuses).
+		Find(&hold).Error
+	return &hold, err
+}
+
+func (r *holdRepository) GetHolds(filter *HoldFilter) ([]model.Hold, int64, error) {
+	var (
+		holds []model.Hold
+		total int64
+	)
+
+	query := r.db.Model(&model.Hold{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.BookID != 0 {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	switch filter.Status {
+	case "":
+	case HoldStatusActive:
+		query = query.Where("status IN (?)", activeHoldStatuses)
+	default:
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return holds, total, err
+	}
+
+	err := preloadHold(query).Order("id").Offset(filter.Offset).Limit(filter.Limit).Find(&holds).Error
+	return holds, total, err
+}
+
+// CountHoldsAhead counts the waiting holds served before hold. Holds that
+// expired at now are skipped like releaseCopy does, even before ExpireHolds
+// ended them.
+func (r *holdRepository) CountHoldsAhead(hold *model.Hold, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Hold{}).
+		Where("book_id = ? AND status = ? AND id < ? AND expires_at >= ?", hold.BookID, constant.HOLD_STATUS_WAITING, hold.ID, now).
+		Count(&count).Error
+	return count, err
+}
+
+// CancelHold passes the copy of a ready hold on to the next hold in the
+// queue. It returns constant.HOLD_NOT_ACTIVE when the hold was fulfilled or
+// ended in the meantime.
+func (r *holdRepository) CancelHold(hold *model.Hold, now, pickupBy time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return endHold(tx, hold, constant.HOLD_STATUS_CANCELLED, now, pickupBy)
+	})
+}
+
+func (r *holdRepository) CancelUserHolds(userId uint, now, pickupBy time.Time) error {
+	var holds []model.Hold
+	if err := r.db.Where("user_id = ? AND status IN (?)", userId, activeHoldStatuses).Find(&holds).Error; err != nil {
+		return err
+	}
+
+	for i := range holds {
+		if err := r.CancelHold(&holds[i], now, pickupBy); err != nil && err != constant.HOLD_NOT_ACTIVE {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpireHolds ends the waiting holds past their expiry and the ready holds
+// nobody picked up in time, whose copies go to the next hold in the queue.
+func (r *holdRepository) ExpireHolds(now, pickupBy time.Time) (int, error) {
+	expired := r.db.Model(&model.Hold{}).
+		Where("status = ? AND expires_at < ?", constant.HOLD_STATUS_WAITING, now).
+		UpdateColumns(endedHold(constant.HOLD_STATUS_EXPIRED))
+	if expired.Error != nil {
+		return 0, expired.Error
+	}
+	count := int(expired.RowsAffected)
+
+	var holds []model.Hold
+	if err := r.db.Where("status = ? AND pickup_by < ?", constant.HOLD_STATUS_READY, now).Find(&holds).Error; err != nil {
+		return count, err
+	}
+	for i := range holds {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			return endHold(tx, &holds[i], constant.HOLD_STATUS_EXPIRED, now, pickupBy)
+		})
+		if err == constant.HOLD_NOT_ACTIVE {
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ReleaseCopy hands an available copy to the next hold in its book's queue,
+// if there is one. A copy that was checked out in the meantime is left alone.
+func (r *holdRepository) ReleaseCopy(bookCopy *model.BookCopy, now, pickupBy time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return releaseCopy(tx, bookCopy.ID, bookCopy.BookID, constant.COPY_STATUS_AVAILABLE, now, pickupBy)
+	})
+	if err == constant.COPY_IN_USE {
+		return nil
+	}
+	return err
+}
+
+// endedHold are the columns of a hold leaving the queue with status. Clearing
+// active frees the member's slot in idx_holds_active.
+func endedHold(status string) map[string]interface{} {
+	return map[string]interface{}{
+		"status": status,
+		"active": nil,
+	}
+}
+
+func endHold(tx *gorm.DB, hold *model.Hold, status string, now, pickupBy time.Time) error {
+	var current model.Hold
+	if err := tx.Find(&current, hold.ID).Error; err != nil {
+		return err
+	}
+
+	ended := tx.Model(&model.Hold{}).
+		Where("id = ? AND status = ?", current.ID, current.Status).
+		Where("status IN (?)", activeHoldStatuses).
+		UpdateColumns(endedHold(status))
+	if ended.Error != nil {
+		return ended.Error
+	}
+	if ended.RowsAffected != 1 {
+		return constant.HOLD_NOT_ACTIVE
+	}
+	hold.Status = status
+
+	if current.Status == constant.HOLD_STATUS_READY && current.BookCopyID != nil {
+		return releaseCopy(tx, *current.BookCopyID, current.BookID, constant.COPY_STATUS_ON_HOLD, now, pickupBy)
+	}
+	return nil
+}
+
+// releaseCopy moves a copy out of the from status. The copy is put on hold
+// for the first waiting hold of its book that hasn't expired, or back on the
+// shelf when nobody is waiting. A hold that ended before it could be claimed
+// passes the copy on to the next one. It returns constant.COPY_IN_USE when
+// the copy left the from status in the meantime.
+func releaseCopy(tx *gorm.DB, copyId, bookId uint, from string, now, pickupBy time.Time) error {
+	var lastId uint
+	for {
+		var hold model.Hold
+		err := tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("book_id = ? AND status = ? AND expires_at >= ? AND id > ?", bookId, constant.HOLD_STATUS_WAITING, now, lastId).
+			Order("id").First(&hold).Error
+		if err == constant.RECORD_NOT_FOUND {
+			return moveCopy(tx, copyId, from, constant.COPY_STATUS_AVAILABLE)
+		}
+		if err != nil {
+			return err
+		}
+
+		claimed := tx.Model(&model.Hold{}).
+			Where("id = ? AND status = ?", hold.ID, constant.HOLD_STATUS_WAITING).
+			UpdateColumns(map[string]interface{}{
+				"status":       constant.HOLD_STATUS_READY,
+				"book_copy_id": copyId,
+				"ready_at":     now,
+				"pickup_by":    pickupBy,
+			})
+		if claimed.Error != nil {
+			return claimed.Error
+		}
+		if claimed.RowsAffected == 1 {
+			return moveCopy(tx, copyId, from, constant.COPY_STATUS_ON_HOLD)
+		}
+		lastId = hold.ID
+	}
+}
+
+func moveCopy(tx *gorm.DB, copyId uint, from, to string) error {
+	if from == to {
+		return nil
+	}
+	moved := tx.Model(&model.BookCopy{}).
+		Where("id = ? AND status = ?", copyId, from).
+		UpdateColumn("status", to)
+	if moved.Error != nil {
+		return moved.Error
+	}
+	if moved.RowsAffected != 1 {
+		return constant.COPY_IN_USE
+	}
+	return nil
+}
+
+// isDuplicateKey tells whether err is MySQL refusing a row that violates a
+// unique index.
+func isDuplicateKey(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && mysqlErr.Number == mysqlDuplicateEntry
+}
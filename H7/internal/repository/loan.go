@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/jinzhu/gorm"
+)
+
+type LoanRepositoryInterface interface {
+	CheckoutBook(loan *model.Loan, barcode string, pickupBy time.Time) (*model.Loan, error)
+	GetLoanById(id int) (*model.Loan, error)
+	GetLoans(filter *LoanFilter) ([]model.Loan, int64, error)
+	CountActiveLoans(userId, bookId uint) (int64, error)
+	ReturnLoan(loan *model.Loan, returnedAt, pickupBy time.Time, fines []model.FineEntry) error
+	MarkLoanLost(loan *model.Loan, lostAt time.Time, fines []model.FineEntry) error
+	RenewLoan(loan *model.Loan, dueAt time.Time) error
+}
+
+// Loan statuses accepted by LoanFilter.
+const (
+	LoanStatusActive   = "active"
+	LoanStatusReturned = "returned"
+	LoanStatusOverdue  = "overdue"
+)
+
+// LoanFilter narrows and pages a loan listing. Zero values disable a filter.
+type LoanFilter struct {
+	UserID uint
+	BookID uint
+	Status string
+	Now    time.Time
+	Offset int
+	Limit  int
+}
+
+// NoLimit as the Limit of a listing returns every matching row.
+const NoLimit = -1
+
+// checkoutCandidates is how many available copies a checkout tries before it
+// gives up, each one may be taken by a concurrent checkout.
+const checkoutCandidates = 5
+
+// preloadLoan loads the book and copy of loans, even when they were deleted
+// since.
+func preloadLoan(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+	return db.Preload("Book", unscoped).Preload("BookCopy", unscoped)
+}
+
+type loanRepository struct {
+	db *gorm.DB
+}
+
+func InitLoanRepository(db *gorm.DB) LoanRepositoryInterface {
+	return &loanRepository{
+		db: db,
+	}
+}
+
+// CheckoutBook lends an available copy of loan.BookID, or the copy with
+// barcode when it's set. A member whose hold is ready gets the copy put on
+// hold for them, unless another copy is scanned, the held copy then goes to
+// the next hold until pickupBy. Copies are claimed with a conditional update,
+// so a copy can't be lent twice however many checkouts run at once. It
+// returns constant.NO_COPY_AVAILABLE when no copy could be claimed.
+func (r *loanRepository) CheckoutBook(loan *model.Loan, barcode string, pickupBy time.Time) (*model.Loan, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var hold model.Hold
+		err := tx.Preload("BookCopy").
+			Where("user_id = ? AND book_id = ? AND status = ?", loan.UserID, loan.BookID, constant.HOLD_STATUS_READY).
+			Find(&hold).Error
+		if err != nil && err != constant.RECORD_NOT_FOUND {
+			return err
+		}
+
+		if err == nil && hold.BookCopyID != nil && (barcode == "" || barcode == hold.BookCopy.Barcode) {
+			if err := claimCopy(tx, loan, *hold.BookCopyID, constant.COPY_STATUS_ON_HOLD); err != nil {
+				return err
+			}
+		} else {
+			var copies []model.BookCopy
+			query := tx.Where("book_id = ? AND status = ?", loan.BookID, constant.COPY_STATUS_AVAILABLE)
+			if barcode != "" {
+				query = query.Where("barcode = ?", barcode)
+			}
+			if err := query.Order("id").Limit(checkoutCandidates).Find(&copies).Error; err != nil {
+				return err
+			}
+
+			for _, bookCopy := range copies {
+				err := claimCopy(tx, loan, bookCopy.ID, constant.COPY_STATUS_AVAILABLE)
+				if err == nil {
+					break
+				}
+				if err != constant.COPY_IN_USE {
+					return err
+				}
+			}
+			if loan.BookCopyID == 0 {
+				return constant.NO_COPY_AVAILABLE
+			}
+		}
+
+		// the member's hold on the book is served by this loan. A ready hold
+		// on another copy gives its copy to the next member.
+		if hold.ID != 0 && hold.BookCopyID != nil && *hold.BookCopyID != loan.BookCopyID {
+			return endHold(tx, &hold, constant.HOLD_STATUS_FULFILLED, loan.CreatedAt, pickupBy)
+		}
+		return tx.Model(&model.Hold{}).
+			Where("user_id = ? AND book_id = ? AND status IN (?)", loan.UserID, loan.BookID, activeHoldStatuses).
+			UpdateColumns(endedHold(constant.HOLD_STATUS_FULFILLED)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetLoanById(int(loan.ID))
+}
+
+// claimCopy puts a copy in the from status on loan and opens the loan for it.
+func claimCopy(tx *gorm.DB, loan *model.Loan, copyId uint, from string) error {
+	if err := moveCopy(tx, copyId, from, constant.COPY_STATUS_ON_LOAN); err != nil {
+		return err
+	}
+	loan.BookCopyID = copyId
+	return tx.Create(loan).Error
+}
+
+func (r *loanRepository) GetLoanById(id int) (*model.Loan, error) {
+	var loan model.Loan
+	err := preloadLoan(r.db).Find(&loan, id).Error
+	return &loan, err
+}
+
+func (r *loanRepository) GetLoans(filter *LoanFilter) ([]model.Loan, int64, error) {
+	var (
+		loans []model.Loan
+		total int64
+	)
+
+	query := r.db.Model(&model.Loan{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.BookID != 0 {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	switch filter.Status {
+	case LoanStatusActive:
+		query = query.Where("returned_at IS NULL")
+	case LoanStatusReturned:
+		query = query.Where("returned_at IS NOT NULL")
+	case LoanStatusOverdue:
+		query = query.Where("returned_at IS NULL AND due_at < ?", filter.Now)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return loans, total, err
+	}
+
+	order := "id desc"
+	if filter.Status == LoanStatusOverdue {
+		order = "due_at, id"
+	}
+	err := preloadLoan(query).Order(order).Offset(filter.Offset).Limit(filter.Limit).Find(&loans).Error
+	return loans, total, err
+}
+
+func (r *loanRepository) CountActiveLoans(userId, bookId uint) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.Loan{}).Where("user_id = ? AND returned_at IS NULL", userId)
+	if bookId != 0 {
+		query = query.Where("book_id = ?", bookId)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// ReturnLoan closes the loan, charges its fines and puts its copy on hold for
+// the next member in the book's queue until pickupBy, or back on the shelf.
+// It returns constant.LOAN_NOT_ACTIVE when the loan was returned in the
+// meantime.
+func (r *loanRepository) ReturnLoan(loan *model.Loan, returnedAt, pickupBy time.Time, fines []model.FineEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := closeLoan(tx, loan, returnedAt, false, fines); err != nil {
+			return err
+		}
+		return releaseCopy(tx, loan.BookCopyID, loan.BookID, constant.COPY_STATUS_ON_LOAN, returnedAt, pickupBy)
+	})
+}
+
+// MarkLoanLost closes the loan, charges its fines and marks its copy lost. It
+// returns constant.LOAN_NOT_ACTIVE when the loan was returned in the
+// meantime.
+func (r *loanRepository) MarkLoanLost(loan *model.Loan, lostAt time.Time, fines []model.FineEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := closeLoan(tx, loan, lostAt, true, fines); err != nil {
+			return err
+		}
+		return moveCopy(tx, loan.BookCopyID, constant.COPY_STATUS_ON_LOAN, constant.COPY_STATUS_LOST)
+	})
+}
+
+func closeLoan(tx *gorm.DB, loan *model.Loan, closedAt time.Time, lost bool, fines []model.FineEntry) error {
+	closed := tx.Model(&model.Loan{}).
+		Where("id = ? AND returned_at IS NULL", loan.ID).
+		UpdateColumns(map[string]interface{}{
+			"returned_at": closedAt,
+			"lost":        lost,
+		})
+	if closed.Error != nil {
+		return closed.Error
+	}
+	if closed.RowsAffected != 1 {
+		return constant.LOAN_NOT_ACTIVE
+	}
+
+	for i := range fines {
+		if err := tx.Create(&fines[i]).Error; err != nil {
+			return err
+		}
+	}
+	loan.ReturnedAt = &closedAt
+	loan.Lost = lost
+	return nil
+}
+
+// RenewLoan moves the due date and counts the renewal. It returns
+// constant.LOAN_NOT_ACTIVE when the loan was returned or renewed in the
+// meantime.
+func (r *loanRepository) RenewLoan(loan *model.Loan, dueAt time.Time) error {
+	renewed := r.db.Model(&model.Loan{}).
+		Where("id = ? AND returned_at IS NULL AND renewals = ?", loan.ID, loan.Renewals).
+		UpdateColumns(map[string]interface{}{
+			"due_at":   dueAt,
+			"renewals": gorm.Expr("renewals + 1"),
+		})
+	if renewed.Error != nil {
+		return renewed.Error
+	}
+	if renewed.RowsAffected != 1 {
+		return constant.LOAN_NOT_ACTIVE
+	}
+	loan.DueAt = dueAt
+	loan.Renewals++
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type LoginThrottleRepositoryInterface interface {
+	GetLoginThrottle(identifier string) (*model.LoginThrottle, error)
+	RecordLoginFailure(identifier string, window time.Duration) (*model.LoginThrottle, error)
+	LockLoginThrottle(throttle *model.LoginThrottle, until time.Time) error
+	ResetLoginThrottle(identifier string) error
+}
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func InitLoginThrottleRepository(db *gorm.DB) LoginThrottleRepositoryInterface {
+	return &loginThrottleRepository{
+		db: db,
+	}
+}
+
+func (r *loginThrottleRepository) GetLoginThrottle(identifier string) (*model.LoginThrottle, error) {
+	var throttle model.LoginThrottle
+	err := r.db.Where("identifier = ?", identifier).Find(&throttle).Error
+	return &throttle, err
+}
+
+// RecordLoginFailure increments the counter in the database so concurrent
+// failures are never lost. Failures older than window are forgotten unless the
+// key is still locked.
+func (r *loginThrottleRepository) RecordLoginFailure(identifier string, window time.Duration) (*model.LoginThrottle, error) {
+	now := time.Now()
+	throttle := model.LoginThrottle{Identifier: identifier}
+	if err := r.db.Where("identifier = ?", identifier).Attrs(model.LoginThrottle{LastFailedAt: now}).FirstOrCreate(&throttle).Error; err != nil {
+		return nil, err
+	}
+
+	if !throttle.IsLocked(now) && throttle.LastFailedAt.Before(now.Add(-window)) {
+		if err := r.db.Model(&throttle).Updates(map[string]interface{}{"failures": 0, "locked_until": nil}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	err := r.db.Model(&model.LoginThrottle{}).Where("id = ?", throttle.ID).Updates(map[string]interface{}{
+		"failures":       gorm.Expr("failures + 1"),
+		"last_failed_at": now,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetLoginThrottle(identifier)
+}
+
+func (r *loginThrottleRepository) LockLoginThrottle(throttle *model.LoginThrottle, until time.Time) error {
+	throttle.LockedUntil = &until
+	return r.db.Model(&model.LoginThrottle{}).Where("id = ?", throttle.ID).Update("locked_until", until).Error
+}
+
+func (r *loginThrottleRepository) ResetLoginThrottle(identifier string) error {
+	return r.db.Unscoped().Where("identifier = ?", identifier).Delete(&model.LoginThrottle{}).Error
+}
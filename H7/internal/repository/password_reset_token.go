@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type PasswordResetTokenRepositoryInterface interface {
+	CreatePasswordResetToken(token *model.PasswordResetToken) (*model.PasswordResetToken, error)
+	GetPasswordResetTokenByHash(hash string) (*model.PasswordResetToken, error)
+	UsePasswordResetToken(token *model.PasswordResetToken, hashedPassword string) (bool, error)
+	InvalidateUserPasswordResetTokens(userId uint) error
+}
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func InitPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepositoryInterface {
+	return &passwordResetTokenRepository{
+		db: db,
+	}
+}
+
+func (r *passwordResetTokenRepository) CreatePasswordResetToken(token *model.PasswordResetToken) (*model.PasswordResetToken, error) {
+	err := r.db.Create(&token).Error
+	return token, err
+}
+
+func (r *passwordResetTokenRepository) GetPasswordResetTokenByHash(hash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	err := r.db.Where("token_hash = ?", hash).Find(&token).Error
+	return &token, err
+}
+
+// UsePasswordResetToken marks the token as used and sets the password of its
+// user in one transaction. It reports false, changing nothing, when another
+// request already consumed the token.
+func (r *passwordResetTokenRepository) UsePasswordResetToken(token *model.PasswordResetToken, hashedPassword string) (bool, error) {
+	now := time.Now()
+	used := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		used = true
+		return tx.Model(&model.User{}).Where("id = ?", token.UserID).UpdateColumn("password", hashedPassword).Error
+	})
+	if err != nil || !used {
+		return false, err
+	}
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *passwordResetTokenRepository) InvalidateUserPasswordResetTokens(userId uint) error {
+	return r.db.Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Update("used_at", time.Now()).Error
+}
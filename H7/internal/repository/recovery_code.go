@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type RecoveryCodeRepositoryInterface interface {
+	ReplaceRecoveryCodes(userId uint, hashes []string) error
+	UseRecoveryCode(userId uint, hash string) (bool, error)
+	DeleteRecoveryCodes(userId uint) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func InitRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepositoryInterface {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+func (r *recoveryCodeRepository) ReplaceRecoveryCodes(userId uint, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			if err := tx.Create(&model.RecoveryCode{UserID: userId, CodeHash: hash}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode consumes a matching unused code, each code works only once.
+func (r *recoveryCodeRepository) UseRecoveryCode(userId uint, hash string) (bool, error) {
+	res := r.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, hash).
+		Limit(1).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *recoveryCodeRepository) DeleteRecoveryCodes(userId uint) error {
+	return r.db.Unscoped().Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type RefreshTokenRepositoryInterface interface {
+	CreateRefreshToken(token *model.RefreshToken) (*model.RefreshToken, error)
+	GetRefreshTokenByHash(hash string) (*model.RefreshToken, error)
+	RevokeRefreshToken(token *model.RefreshToken) (bool, error)
+	RevokeTokenFamily(familyId string) error
+	RevokeUserRefreshTokens(userId uint) error
+	RevokeSessionRefreshTokens(sessionId uint) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func InitRefreshTokenRepository(db *gorm.DB) RefreshTokenRepositoryInterface {
+	return &refreshTokenRepository{
+		db: db,
+	}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *model.RefreshToken) (*model.RefreshToken, error) {
+	err := r.db.Create(&token).Error
+	return token, err
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenByHash(hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).Find(&token).Error
+	return &token, err
+}
+
+// RevokeRefreshToken only succeeds for a token that is still active, so two
+// concurrent refreshes with the same token cannot both rotate it.
+func (r *refreshTokenRepository) RevokeRefreshToken(token *model.RefreshToken) (bool, error) {
+	now := time.Now()
+	res := r.db.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", token.ID).
+		Update("revoked_at", now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	token.RevokedAt = &now
+	return true, nil
+}
+
+func (r *refreshTokenRepository) RevokeTokenFamily(familyId string) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeUserRefreshTokens(userId uint) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeSessionRefreshTokens(sessionId uint) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionId).
+		Update("revoked_at", time.Now()).Error
+}
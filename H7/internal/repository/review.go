@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"github.com/hansandika/internal/model"
+	"github.com/hansandika/pkg/constant"
+	"github.com/jinzhu/gorm"
+)
+
+type ReviewRepositoryInterface interface {
+	CreateReview(review *model.Review) (*model.Review, error)
+	GetReviewById(bookId, id int) (*model.Review, error)
+	GetUserReview(bookId int, userId uint) (*model.Review, error)
+	GetReviews(bookId int, sort string, offset, limit int) ([]model.Review, int64, error)
+	GetUserReviews(userId uint) ([]model.Review, error)
+	UpdateReview(review *model.Review) (*model.Review, error)
+	DeleteReview(review *model.Review) error
+	HasHelpfulVote(review *model.Review, userId uint) (bool, error)
+	AddHelpfulVote(review *model.Review, userId uint) error
+	RemoveHelpfulVote(review *model.Review, userId uint) error
+}
+
+// ReviewSortHelpful orders reviews by helpful votes, they are newest first
+// otherwise.
+const ReviewSortHelpful = "helpful"
+
+// preloadReview loads the reviewer, anonymized reviewers included.
+func preloadReview(db *gorm.DB) *gorm.DB {
+	return db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func InitReviewRepository(db *gorm.DB) ReviewRepositoryInterface {
+	return &reviewRepository{
+		db: db,
+	}
+}
+
+// CreateReview adds the rating to the book's totals in the same transaction.
+// It returns constant.REVIEW_EXISTS when the member reviewed the book in the
+// meantime.
+func (r *reviewRepository) CreateReview(review *model.Review) (*model.Review, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			if isDuplicateKey(err) {
+				return constant.REVIEW_EXISTS
+			}
+			return err
+		}
+		return updateBookRating(tx, review.BookID, 1, review.Rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetReviewById(int(review.BookID), int(review.ID))
+}
+
+func (r *reviewRepository) GetReviewById(bookId, id int) (*model.Review, error) {
+	var review model.Review
+	err := preloadReview(r.db).Where("book_id = ?", bookId).Find(&review, id).Error
+	return &review, err
+}
+
+func (r *reviewRepository) GetUserReview(bookId int, userId uint) (*model.Review, error) {
+	var review model.Review
+	err := r.db.Where("book_id = ? AND user_id = ?", bookId, userId).Find(&review).Error
+	return &review, err
+}
+
+func (r *reviewRepository) GetReviews(bookId int, sort string, offset, limit int) ([]model.Review, int64, error) {
+	var (
+		reviews []model.Review
+		total   int64
+	)
+
+	query := r.db.Model(&model.Review{}).Where("book_id = ?", bookId)
+	if err := query.Count(&total).Error; err != nil {
+		return reviews, total, err
+	}
+
+	order := "created_at desc, id desc"
+	if sort == ReviewSortHelpful {
+		order = "helpful_count desc, id desc"
+	}
+	err := preloadReview(query).Order(order).Offset(offset).Limit(limit).Find(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *reviewRepository) GetUserReviews(userId uint) ([]model.Review, error) {
+	var reviews []model.Review
+	err := preloadReview(r.db).Where("user_id = ?", userId).Order("id desc").Find(&reviews).Error
+	return reviews, err
+}
+
+// UpdateReview moves the book's rating total from the stored rating to the
+// review's rating in the same transaction. The stored row is locked so
+// concurrent edits apply their changes one after the other.
+func (r *reviewRepository) UpdateReview(review *model.Review) (*model.Review, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var stored model.Review
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Find(&stored, review.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("helpful_count").Save(review).Error; err != nil {
+			return err
+		}
+		return updateBookRating(tx, review.BookID, 0, review.Rating-stored.Rating)
+	})
+	return review, err
+}
+
+// DeleteReview removes the review, its votes and its stored rating from the
+// book's totals. The totals are left alone when a concurrent delete got there
+// first.
+func (r *reviewRepository) DeleteReview(review *model.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var stored model.Review
+		err := tx.Set("gorm:query_option", "FOR UPDATE").Find(&stored, review.ID).Error
+		if err == constant.RECORD_NOT_FOUND {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("review_id = ?", review.ID).Delete(&model.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		deleted := tx.Unscoped().Delete(review)
+		if deleted.Error != nil || deleted.RowsAffected != 1 {
+			return deleted.Error
+		}
+		return updateBookRating(tx, stored.BookID, -1, -stored.Rating)
+	})
+}
+
+func (r *reviewRepository) HasHelpfulVote(review *model.Review, userId uint) (bool, error) {
+	var count int
+	err := r.db.Model(&model.ReviewVote{}).Where("review_id = ? AND user_id = ?", review.ID, userId).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *reviewRepository) AddHelpfulVote(review *model.Review, userId uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.ReviewVote{ReviewID: review.ID, UserID: userId}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(review).UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error; err != nil {
+			return err
+		}
+		review.HelpfulCount++
+		return nil
+	})
+}
+
+func (r *reviewRepository) RemoveHelpfulVote(review *model.Review, userId uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		removed := tx.Unscoped().Where("review_id = ? AND user_id = ?", review.ID, userId).Delete(&model.ReviewVote{})
+		if removed.Error != nil || removed.RowsAffected == 0 {
+			return removed.Error
+		}
+		if err := tx.Model(review).UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error; err != nil {
+			return err
+		}
+		review.HelpfulCount--
+		return nil
+	})
+}
+
+func updateBookRating(tx *gorm.DB, bookId uint, count, sum int) error {
+	return tx.Model(&model.Book{}).Where("id = ?", bookId).UpdateColumns(map[string]interface{}{
+		"rating_count": gorm.Expr("rating_count + ?", count),
+		"rating_sum":   gorm.Expr("rating_sum + ?", sum),
+	}).Error
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type SessionRepositoryInterface interface {
+	CreateSession(session *model.Session) (*model.Session, error)
+	GetSessionById(id uint) (*model.Session, error)
+	GetUserSessions(userId uint) ([]model.Session, error)
+	GetUserSessionHistory(userId uint) ([]model.Session, error)
+	TouchSession(session *model.Session, ip string) error
+	RevokeSession(session *model.Session) error
+	RevokeUserSessions(userId uint) error
+	DeleteUserSessions(userId uint) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func InitSessionRepository(db *gorm.DB) SessionRepositoryInterface {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+func (r *sessionRepository) CreateSession(session *model.Session) (*model.Session, error) {
+	err := r.db.Create(&session).Error
+	return session, err
+}
+
+func (r *sessionRepository) GetSessionById(id uint) (*model.Session, error) {
+	var session model.Session
+	err := r.db.Find(&session, id).Error
+	return &session, err
+}
+
+func (r *sessionRepository) GetUserSessions(userId uint) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userId).Order("last_seen_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) GetUserSessionHistory(userId uint) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("user_id = ?", userId).Order("created_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) TouchSession(session *model.Session, ip string) error {
+	session.LastSeenAt = time.Now()
+	session.IP = ip
+	return r.db.Model(&model.Session{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+		"last_seen_at": session.LastSeenAt,
+		"ip":           ip,
+	}).Error
+}
+
+func (r *sessionRepository) RevokeSession(session *model.Session) error {
+	now := time.Now()
+	session.RevokedAt = &now
+	return r.db.Model(&model.Session{}).Where("id = ? AND revoked_at IS NULL", session.ID).Update("revoked_at", now).Error
+}
+
+func (r *sessionRepository) RevokeUserSessions(userId uint) error {
+	return r.db.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) DeleteUserSessions(userId uint) error {
+	return r.db.Unscoped().Where("user_id = ?", userId).Delete(&model.Session{}).Error
+}
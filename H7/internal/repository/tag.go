@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"github.com/hansandika/internal/model"
+	"github.com/jinzhu/gorm"
+)
+
+type TagRepositoryInterface interface {
+	CreateTag(tag *model.Tag) (*model.Tag, error)
+	GetTagById(id int) (*model.Tag, error)
+	GetTagByName(name string) (*model.Tag, error)
+	GetAllTags(prefix string, offset, limit int) ([]model.Tag, int64, error)
+	UpdateTag(tag *model.Tag) (*model.Tag, error)
+	DeleteTag(tag *model.Tag) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func InitTagRepository(db *gorm.DB) TagRepositoryInterface {
+	return &tagRepository{
+		db: db,
+	}
+}
+
+func (r *tagRepository) CreateTag(tag *model.Tag) (*model.Tag, error) {
+	err := r.db.Create(&tag).Error
+	return tag, err
+}
+
+func (r *tagRepository) GetTagById(id int) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Find(&tag, id).Error
+	return &tag, err
+}
+
+func (r *tagRepository) GetTagByName(name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Where("name = ?", name).Find(&tag).Error
+	return &tag, err
+}
+
+func (r *tagRepository) GetAllTags(prefix string, offset, limit int) ([]model.Tag, int64, error) {
+	var (
+		tags  []model.Tag
+		total int64
+	)
+
+	query := r.db.Model(&model.Tag{})
+	if prefix != "" {
+		query = query.Where("name LIKE ?", escapeLike(prefix)+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return tags, total, err
+	}
+
+	err := query.Order("name").Offset(offset).Limit(limit).Find(&tags).Error
+	return tags, total, err
+}
+
+func (r *tagRepository) UpdateTag(tag *model.Tag) (*model.Tag, error) {
+	err := r.db.Save(&tag).Error
+	return tag, err
+}
+
+func (r *tagRepository) DeleteTag(tag *model.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(tag).Association("Books").Clear().Error; err != nil {
+			return err
+		}
+		// tag names are unique, so a soft deleted tag would block the name
+		return tx.Unscoped().Delete(tag).Error
+	})
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/hansandika/internal/model"
 	"github.com/jinzhu/gorm"
 )
@@ -11,8 +13,11 @@ type UserRepositoryInterface interface {
 	GetUserById(id int) (*model.User, error)
 	GetUserByEmail(email string) (*model.User, error)
 	GetAllUsers() ([]model.User, error)
+	CountUsersByRole(role string) (int64, error)
 	UpdateUser(user *model.User) (*model.User, error)
+	UseTOTPStep(user *model.User, step int64) (bool, error)
 	DeleteUser(user *model.User) error
+	GetUsersPendingAnonymization(before time.Time) ([]model.User, error)
 }
 
 type userRepository struct {
@@ -65,12 +70,41 @@ func (r *userRepository) GetAllUsers() ([]model.User, error) {
 	return users, err
 }
 
+func (r *userRepository) CountUsersByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Where("CONCAT(',', roles, ',') LIKE ?", "%,"+role+",%").Count(&count).Error
+	return count, err
+}
+
 func (r *userRepository) UpdateUser(user *model.User) (*model.User, error) {
 	err := r.db.Save(&user).Error
 	return user, err
 }
 
+// UseTOTPStep records step as the last TOTP step the user logged in with,
+// unless the same or a later step was used already. Each code works only once
+// however many logins race with it.
+func (r *userRepository) UseTOTPStep(user *model.User, step int64) (bool, error) {
+	res := r.db.Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		UpdateColumn("totp_last_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected != 1 {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
 func (r *userRepository) DeleteUser(user *model.User) error {
 	err := r.db.Delete(&user).Error
 	return err
 }
+
+func (r *userRepository) GetUsersPendingAnonymization(before time.Time) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", before).Find(&users).Error
+	return users, err
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"time"
+
+	"github.com/hansandika/internal/app/hold"
+	"github.com/hansandika/internal/app/user"
+	"github.com/hansandika/internal/dto"
 	"github.com/hansandika/internal/factory"
 	"github.com/hansandika/internal/http"
 	"github.com/hansandika/internal/middleware"
+	jwtUtil "github.com/hansandika/internal/pkg/util"
+	"github.com/hansandika/pkg/util"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo"
 )
@@ -12,6 +19,28 @@ func main() {
 	godotenv.Load()
 	f := factory.NewFactory()
 	e := echo.New()
+
+	if _, err := jwtUtil.GetKeySet(); err != nil {
+		e.Logger.Fatal(err)
+	}
+	if _, err := util.GetPasswordPolicy(); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	if email := util.Getenv("BOOTSTRAP_ADMIN_EMAIL", ""); email != "" {
+		_, err := user.NewUsecase(f).BootstrapAdmin(&dto.NewUser{
+			Name:     util.Getenv("BOOTSTRAP_ADMIN_NAME", "Administrator"),
+			Email:    email,
+			Password: util.Getenv("BOOTSTRAP_ADMIN_PASSWORD", ""),
+		})
+		if err != nil {
+			e.Logger.Fatal(err.ErrorMessage)
+		}
+	}
+
+	user.StartAnonymizer(f, util.GetenvDuration("ACCOUNT_ANONYMIZE_INTERVAL", time.Hour))
+	hold.StartExpirer(f, util.GetenvDuration("HOLD_EXPIRE_INTERVAL", 15*time.Minute))
+
 	middleware.LogMiddleware(e)
 	http.NewHttp(e, f)
 	e.Logger.Fatal(e.Start(":8080"))
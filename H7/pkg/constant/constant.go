@@ -1,9 +1,79 @@
 package constant
 
 import (
+	"errors"
+
 	"github.com/jinzhu/gorm"
 )
 
 var (
 	RECORD_NOT_FOUND = gorm.ErrRecordNotFound
+	// returned by the repositories when a conditional update lost a race
+	NO_COPY_AVAILABLE = errors.New("No copies available")
+	COPY_IN_USE       = errors.New("Copy is on loan or on hold")
+	LOAN_NOT_ACTIVE   = errors.New("Loan is no longer active")
+	HOLD_NOT_ACTIVE   = errors.New("Hold is no longer active")
+	HOLD_EXISTS       = errors.New("Hold already placed")
+	COPY_AVAILABLE    = errors.New("Book has available copies")
+	REVIEW_EXISTS     = errors.New("You already reviewed this book")
+	ISBN_EXISTS       = errors.New("Book with this ISBN already exists")
+)
+
+const (
+	ROLE_MEMBER    = "member"
+	ROLE_LIBRARIAN = "librarian"
+	ROLE_ADMIN     = "admin"
+)
+
+// Scopes limit what a personal API key may do on behalf of its owner. Bearer
+// tokens are not scoped.
+const (
+	SCOPE_BOOKS_READ    = "books:read"
+	SCOPE_BOOKS_WRITE   = "books:write"
+	SCOPE_LOANS_READ    = "loans:read"
+	SCOPE_LOANS_WRITE   = "loans:write"
+	SCOPE_REVIEWS_WRITE = "reviews:write"
+	SCOPE_USERS_READ    = "users:read"
+	SCOPE_USERS_WRITE   = "users:write"
+	SCOPE_USERS_ADMIN   = "users:admin"
+)
+
+// A copy is on loan only while a loan is open for it and on hold only while
+// it waits for pickup by a ready hold. Lost and in repair copies can't be
+// lent.
+const (
+	COPY_STATUS_AVAILABLE = "available"
+	COPY_STATUS_ON_LOAN   = "on_loan"
+	COPY_STATUS_ON_HOLD   = "on_hold"
+	COPY_STATUS_LOST      = "lost"
+	COPY_STATUS_IN_REPAIR = "in_repair"
+)
+
+// A hold waits in its book's queue until a copy is assigned to it, it's then
+// ready for pickup until it's fulfilled by a checkout or expires.
+const (
+	HOLD_STATUS_WAITING   = "waiting"
+	HOLD_STATUS_READY     = "ready"
+	HOLD_STATUS_FULFILLED = "fulfilled"
+	HOLD_STATUS_CANCELLED = "cancelled"
+	HOLD_STATUS_EXPIRED   = "expired"
+)
+
+// Charges are positive fine entries and credits negative ones, a user's
+// balance is the sum of their entries.
+const (
+	FINE_TYPE_OVERDUE    = "overdue_fee"
+	FINE_TYPE_LOST_ITEM  = "lost_item"
+	FINE_TYPE_PAYMENT    = "payment"
+	FINE_TYPE_WAIVER     = "waiver"
+	FINE_TYPE_ADJUSTMENT = "adjustment"
+)
+
+// Import rows are valid when a dry run would have created them.
+const (
+	IMPORT_STATUS_CREATED   = "created"
+	IMPORT_STATUS_VALID     = "valid"
+	IMPORT_STATUS_INVALID   = "invalid"
+	IMPORT_STATUS_DUPLICATE = "duplicate"
+	IMPORT_STATUS_FAILED    = "failed"
 )
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"github.com/hansandika/pkg/util"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// NewMailer picks the implementation from MAILER_DRIVER. The outbox driver is
+// the default so local development never sends real mail.
+func NewMailer() Mailer {
+	from := util.Getenv("MAIL_FROM", "no-reply@localhost")
+	switch util.Getenv("MAILER_DRIVER", "outbox") {
+	case "smtp":
+		return NewSMTPMailer(
+			util.Getenv("SMTP_HOST", "localhost"),
+			util.Getenv("SMTP_PORT", "587"),
+			util.Getenv("SMTP_USERNAME", ""),
+			util.Getenv("SMTP_PASSWORD", ""),
+			from,
+		)
+	default:
+		return NewOutboxMailer(util.Getenv("MAIL_OUTBOX_DIR", "outbox"), from)
+	}
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// outboxMailer writes every message as an .eml file instead of delivering it.
+type outboxMailer struct {
+	dir     string
+	from    string
+	counter uint64
+}
+
+func NewOutboxMailer(dir, from string) Mailer {
+	return &outboxMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *outboxMailer) Send(msg *Message) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%d.eml", time.Now().UnixNano(), atomic.AddUint64(&m.counter, 1))
+	return ioutil.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0644)
+}
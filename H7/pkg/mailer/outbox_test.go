@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxMailerSend(t *testing.T) {
+	asserts := assert.New(t)
+	dir := t.TempDir()
+
+	m := NewOutboxMailer(dir, "library@example.com")
+	err := m.Send(&Message{
+		To:      []string{"william@gmail.com"},
+		Subject: "Reset your password",
+		Body:    "token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(files, 1) {
+		content, err := ioutil.ReadFile(files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		asserts.Contains(string(content), "To: william@gmail.com")
+		asserts.Contains(string(content), "Subject: Reset your password")
+	}
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/hansandika/pkg/util"
+)
+
+// Storage keeps uploaded files under slash separated keys and tells where
+// clients can download them from.
+type Storage interface {
+	Put(key, contentType string, data []byte) error
+	Delete(key string) error
+	URL(key string) string
+}
+
+// NewStorage picks the implementation from STORAGE_DRIVER. Files are kept on
+// the local filesystem by default, the s3 driver works with any S3 compatible
+// service reachable at S3_ENDPOINT.
+func NewStorage() Storage {
+	switch util.Getenv("STORAGE_DRIVER", "local") {
+	case "s3":
+		return NewS3Storage(&S3Config{
+			Endpoint:        util.Getenv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          util.Getenv("S3_REGION", "us-east-1"),
+			Bucket:          util.Getenv("S3_BUCKET", ""),
+			AccessKeyID:     util.Getenv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: util.Getenv("S3_SECRET_ACCESS_KEY", ""),
+			PublicURL:       util.Getenv("S3_PUBLIC_URL", ""),
+		})
+	default:
+		return NewLocalStorage(LocalDir(), util.Getenv("STORAGE_PUBLIC_URL", LocalPrefix))
+	}
+}
+
+// LocalPrefix is the path the local storage directory is served under.
+const LocalPrefix = "/uploads"
+
+func LocalDir() string {
+	return util.Getenv("STORAGE_LOCAL_DIR", "uploads")
+}
+
+// IsLocal reports whether files have to be served by this application.
+func IsLocal() bool {
+	return util.Getenv("STORAGE_DRIVER", "local") == "local"
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
@@ -1,13 +1,178 @@
 package util
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
 
-func HashPassword(plainPassword string) (hashedPassword string, err error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(plainPassword), 10)
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher produces self-describing hashes: the algorithm and its
+// parameters are encoded in the hash, so any stored hash can be verified
+// after the configuration changed.
+type PasswordHasher interface {
+	Hash(plainPassword string) (string, error)
+	Compare(plainPassword, hashedPassword string) bool
+	// NeedsRehash reports whether a hash this hasher understands was made
+	// with parameters other than the current ones.
+	NeedsRehash(hashedPassword string) bool
+	Supports(hashedPassword string) bool
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(plainPassword string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(plainPassword), h.cost)
 	return string(bytes), err
 }
 
-func CompareHashPassword(plainPassword, hashedPassword string) bool {
+func (h *bcryptHasher) Compare(plainPassword, hashedPassword string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
 	return err == nil
 }
+
+func (h *bcryptHasher) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != h.cost
+}
+
+func (h *bcryptHasher) Supports(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") || strings.HasPrefix(hashedPassword, "$2b$") || strings.HasPrefix(hashedPassword, "$2y$")
+}
+
+type argon2idHasher struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	keyLen  uint32
+	saltLen int
+}
+
+func NewArgon2idHasher(memory uint32, time uint32, threads uint8) PasswordHasher {
+	return &argon2idHasher{
+		memory:  memory,
+		time:    time,
+		threads: threads,
+		keyLen:  32,
+		saltLen: 16,
+	}
+}
+
+// Hash encodes the result in the PHC string format used by the reference
+// implementation: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (h *argon2idHasher) Hash(plainPassword string) (string, error) {
+	salt := make([]byte, h.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(plainPassword), salt, h.time, h.memory, h.threads, h.keyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.time, h.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Compare(plainPassword, hashedPassword string) bool {
+	params, salt, key, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return false
+	}
+	other := argon2.IDKey([]byte(plainPassword), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+func (h *argon2idHasher) NeedsRehash(hashedPassword string) bool {
+	params, _, key, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return params.memory != h.memory || params.time != h.time || params.threads != h.threads || uint32(len(key)) != h.keyLen
+}
+
+func (h *argon2idHasher) Supports(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$argon2id$")
+}
+
+func decodeArgon2id(hashedPassword string) (*argon2idHasher, []byte, []byte, error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+
+	params := &argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return params, salt, key, nil
+}
+
+func getenvUint(key string, fallback uint64) uint64 {
+	val, err := strconv.ParseUint(Getenv(key, ""), 10, 32)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// DefaultPasswordHasher is configured with PASSWORD_HASH_ALGORITHM (bcrypt or
+// argon2id) and the BCRYPT_* / ARGON2_* parameters.
+func DefaultPasswordHasher() PasswordHasher {
+	switch Getenv("PASSWORD_HASH_ALGORITHM", "bcrypt") {
+	case "argon2id":
+		return NewArgon2idHasher(
+			uint32(getenvUint("ARGON2_MEMORY", 64*1024)),
+			uint32(getenvUint("ARGON2_TIME", 3)),
+			uint8(getenvUint("ARGON2_THREADS", 2)),
+		)
+	default:
+		return NewBcryptHasher(int(getenvUint("BCRYPT_COST", 10)))
+	}
+}
+
+func HashPassword(plainPassword string) (hashedPassword string, err error) {
+	return DefaultPasswordHasher().Hash(plainPassword)
+}
+
+// CompareHashPassword verifies against whichever algorithm produced the hash,
+// not the one currently configured.
+func CompareHashPassword(plainPassword, hashedPassword string) bool {
+	for _, hasher := range []PasswordHasher{NewBcryptHasher(bcrypt.DefaultCost), NewArgon2idHasher(0, 0, 0)} {
+		if hasher.Supports(hashedPassword) {
+			return hasher.Compare(plainPassword, hashedPassword)
+		}
+	}
+	return false
+}
+
+// NeedsRehash is true when the hash was made with another algorithm or other
+// parameters than the configured ones.
+func NeedsRehash(hashedPassword string) bool {
+	hasher := DefaultPasswordHasher()
+	return !hasher.Supports(hashedPassword) || hasher.NeedsRehash(hashedPassword)
+}
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	asserts := assert.New(t)
+	hasher := NewArgon2idHasher(8*1024, 1, 1)
+
+	hashed, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.True(strings.HasPrefix(hashed, "$argon2id$v=19$m=8192,t=1,p=1$"))
+	asserts.True(hasher.Compare("correct horse", hashed))
+	asserts.False(hasher.Compare("wrong horse", hashed))
+	asserts.False(hasher.NeedsRehash(hashed))
+	asserts.True(NewArgon2idHasher(16*1024, 1, 1).NeedsRehash(hashed))
+}
+
+func TestNeedsRehashAcrossAlgorithms(t *testing.T) {
+	asserts := assert.New(t)
+
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	t.Setenv("BCRYPT_COST", "4")
+	bcryptHash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.False(NeedsRehash(bcryptHash))
+
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	t.Setenv("ARGON2_MEMORY", "8192")
+	t.Setenv("ARGON2_TIME", "1")
+	asserts.True(NeedsRehash(bcryptHash))
+	// stored hashes keep verifying after the configuration changed
+	asserts.True(CompareHashPassword("correct horse", bcryptHash))
+
+	argonHash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.False(NeedsRehash(argonHash))
+	asserts.True(CompareHashPassword("correct horse", argonHash))
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	asserts := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := "password123\n" + sha1Hex("letmein2020") + ":42\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := NewPasswordPolicy(8, 64, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asserts.Error(policy.Validate("short", "william@gmail.com"))
+	asserts.Error(policy.Validate("William@Gmail.com", "william@gmail.com"))
+	asserts.Error(policy.Validate("password123", "william@gmail.com"))
+	asserts.Error(policy.Validate("letmein2020", "william@gmail.com"))
+	asserts.NoError(policy.Validate("ayamgoreng", "william@gmail.com"))
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+
+	// registered with image.Decode
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// SniffImage detects JPEG, PNG and WebP data from its content, whatever the
+// client claimed it to be.
+func SniffImage(data []byte) (contentType, ext string, ok bool) {
+	contentType = http.DetectContentType(data)
+	ext, ok = imageExtensions[contentType]
+	return contentType, ext, ok
+}
+
+// DecodeImage reads the header first so images larger than maxDimension on
+// either side are refused before their pixels are allocated.
+func DecodeImage(data []byte, maxDimension int) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width > maxDimension || config.Height > maxDimension {
+		return nil, errors.New("image dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Thumbnail scales img down to width, keeping its aspect ratio, and encodes
+// it as JPEG. Smaller images keep their size and transparency is flattened
+// onto white.
+func Thumbnail(img image.Image, width int) ([]byte, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffImage(t *testing.T) {
+	asserts := assert.New(t)
+
+	contentType, ext, ok := SniffImage(encodePNG(t, 2, 2))
+	asserts.True(ok)
+	asserts.Equal("image/png", contentType)
+	asserts.Equal("png", ext)
+
+	_, _, ok = SniffImage([]byte("GIF89a"))
+	asserts.False(ok)
+	_, _, ok = SniffImage([]byte("<html></html>"))
+	asserts.False(ok)
+}
+
+func TestDecodeImageThumbnail(t *testing.T) {
+	asserts := assert.New(t)
+
+	_, err := DecodeImage(encodePNG(t, 400, 200), 300)
+	asserts.Error(err)
+
+	img, err := DecodeImage(encodePNG(t, 400, 200), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumb, err := Thumbnail(img, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+	if asserts.NoError(err) {
+		asserts.Equal(100, config.Width)
+		asserts.Equal(50, config.Height)
+	}
+
+	thumb, err = Thumbnail(img, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err = jpeg.DecodeConfig(bytes.NewReader(thumb))
+	if asserts.NoError(err) {
+		asserts.Equal(400, config.Width)
+	}
+}
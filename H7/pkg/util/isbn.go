@@ -0,0 +1,105 @@
+package util
+
+import (
+	"strings"
+)
+
+// NormalizeISBN removes the hyphens and spaces an ISBN is usually printed
+// with and upper cases a trailing x check digit.
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
+
+// IsValidISBN10 checks the length, the characters and the mod 11 check digit
+// of a normalized ISBN-10.
+func IsValidISBN10(isbn string) bool {
+	if len(isbn) != 10 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 10; i++ {
+		c := isbn[i]
+		var digit int
+		switch {
+		case c >= '0' && c <= '9':
+			digit = int(c - '0')
+		case c == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+		sum += digit * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// IsValidISBN13 checks the length, the characters and the mod 10 check digit
+// of a normalized ISBN-13.
+func IsValidISBN13(isbn string) bool {
+	if len(isbn) != 13 || !(strings.HasPrefix(isbn, "978") || strings.HasPrefix(isbn, "979")) {
+		return false
+	}
+	for i := 0; i < 13; i++ {
+		if isbn[i] < '0' || isbn[i] > '9' {
+			return false
+		}
+	}
+	return isbn13CheckDigit(isbn[:12]) == isbn[12]
+}
+
+// IsValidISBN accepts a valid ISBN-10 or ISBN-13, hyphens and spaces included.
+func IsValidISBN(isbn string) bool {
+	isbn = NormalizeISBN(isbn)
+	return IsValidISBN10(isbn) || IsValidISBN13(isbn)
+}
+
+// ISBN10To13 converts a valid normalized ISBN-10 to its 978 ISBN-13.
+func ISBN10To13(isbn string) string {
+	body := "978" + isbn[:9]
+	return body + string(isbn13CheckDigit(body))
+}
+
+// ISBN13To10 converts a valid normalized ISBN-13 to ISBN-10. Only 978 ISBNs
+// have an ISBN-10, for the others it returns false.
+func ISBN13To10(isbn string) (string, bool) {
+	if !strings.HasPrefix(isbn, "978") {
+		return "", false
+	}
+	body := isbn[3:12]
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X", true
+	}
+	return body + string(rune('0'+check)), true
+}
+
+// ParseISBN returns both forms of a valid ISBN. isbn10 is empty for ISBN-13s
+// without an ISBN-10.
+func ParseISBN(isbn string) (isbn10, isbn13 string, ok bool) {
+	isbn = NormalizeISBN(isbn)
+	switch {
+	case IsValidISBN10(isbn):
+		return isbn, ISBN10To13(isbn), true
+	case IsValidISBN13(isbn):
+		isbn10, _ = ISBN13To10(isbn)
+		return isbn10, isbn, true
+	default:
+		return "", "", false
+	}
+}
+
+func isbn13CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(body[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidISBN(t *testing.T) {
+	asserts := assert.New(t)
+
+	asserts.True(IsValidISBN("0-261-10320-2"))
+	asserts.True(IsValidISBN("978-0-261-10320-7"))
+	asserts.True(IsValidISBN("0-8044-2957-x"))
+	asserts.False(IsValidISBN("0-261-10320-3"))
+	asserts.False(IsValidISBN("978-0-261-10320-8"))
+	asserts.False(IsValidISBN("12345"))
+}
+
+func TestParseISBN(t *testing.T) {
+	asserts := assert.New(t)
+
+	isbn10, isbn13, ok := ParseISBN("0-261-10320-2")
+	asserts.True(ok)
+	asserts.Equal("0261103202", isbn10)
+	asserts.Equal("9780261103207", isbn13)
+
+	isbn10, isbn13, ok = ParseISBN("9780804429573")
+	asserts.True(ok)
+	asserts.Equal("080442957X", isbn10)
+	asserts.Equal("9780804429573", isbn13)
+
+	isbn10, _, ok = ParseISBN("979-10-90636-07-1")
+	asserts.True(ok)
+	asserts.Empty(isbn10)
+}
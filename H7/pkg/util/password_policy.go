@@ -0,0 +1,112 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	breached  map[string]struct{}
+}
+
+var (
+	passwordPolicy     *PasswordPolicy
+	passwordPolicyErr  error
+	passwordPolicyOnce sync.Once
+)
+
+// GetPasswordPolicy is configured with PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH
+// and PASSWORD_BREACHED_LIST, a local file with one password per line.
+func GetPasswordPolicy() (*PasswordPolicy, error) {
+	passwordPolicyOnce.Do(func() {
+		passwordPolicy, passwordPolicyErr = NewPasswordPolicy(
+			int(getenvUint("PASSWORD_MIN_LENGTH", 8)),
+			int(getenvUint("PASSWORD_MAX_LENGTH", 128)),
+			Getenv("PASSWORD_BREACHED_LIST", ""),
+		)
+	})
+	return passwordPolicy, passwordPolicyErr
+}
+
+// NewPasswordPolicy loads the breached list into memory. Lines may be plain
+// passwords or upper case SHA-1 hashes as published by Have I Been Pwned,
+// optionally followed by ":<count>".
+func NewPasswordPolicy(minLength, maxLength int, breachedListPath string) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		MinLength: minLength,
+		MaxLength: maxLength,
+		breached:  map[string]struct{}{},
+	}
+	if breachedListPath == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(breachedListPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if idx := strings.LastIndex(line, ":"); idx == 40 {
+			line = line[:idx]
+		}
+		if !isSHA1Hex(line) {
+			line = sha1Hex(line)
+		}
+		policy.breached[strings.ToUpper(line)] = struct{}{}
+	}
+	return policy, scanner.Err()
+}
+
+func (p *PasswordPolicy) Validate(password string, email string) error {
+	length := len([]rune(password))
+	if length < p.MinLength {
+		return errors.New("Password must be at least " + strconv.Itoa(p.MinLength) + " characters")
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return errors.New("Password must be at most " + strconv.Itoa(p.MaxLength) + " characters")
+	}
+	if email != "" && strings.EqualFold(strings.TrimSpace(password), strings.TrimSpace(email)) {
+		return errors.New("Password must not be the same as the email")
+	}
+	if _, ok := p.breached[sha1Hex(password)]; ok {
+		return errors.New("Password has appeared in a data breach, please choose another one")
+	}
+	return nil
+}
+
+func ValidatePassword(password string, email string) error {
+	policy, err := GetPasswordPolicy()
+	if err != nil {
+		return fmt.Errorf("password policy: %w", err)
+	}
+	return policy.Validate(password, email)
+}
+
+func sha1Hex(val string) string {
+	sum := sha1.Sum([]byte(val))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1Hex(val string) bool {
+	if len(val) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(val)
+	return err == nil
+}
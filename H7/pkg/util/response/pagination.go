@@ -0,0 +1,53 @@
+package response
+
+import (
+	"net/url"
+	"strconv"
+)
+
+type Pagination struct {
+	Total     int64  `json:"total"`
+	Page      int    `json:"page"`
+	PerPage   int    `json:"per_page"`
+	PageCount int    `json:"page_count"`
+	Next      string `json:"next,omitempty"`
+	Prev      string `json:"prev,omitempty"`
+}
+
+// NewPagination describes the window [offset, offset+limit) of total records.
+// The next and prev links reuse the request URL and keep the paging style of
+// the request: offset/limit when it used them, page/per_page otherwise.
+func NewPagination(requestURL *url.URL, offset, limit int, total int64) *Pagination {
+	p := &Pagination{
+		Total:   total,
+		Page:    offset/limit + 1,
+		PerPage: limit,
+	}
+	p.PageCount = int((total + int64(limit) - 1) / int64(limit))
+
+	if int64(offset+limit) < total {
+		p.Next = pageLink(requestURL, offset+limit, limit)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		p.Prev = pageLink(requestURL, prev, limit)
+	}
+	return p
+}
+
+func pageLink(requestURL *url.URL, offset, limit int) string {
+	link := *requestURL
+	query := link.Query()
+	if query.Has("offset") || query.Has("limit") {
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(limit))
+	} else {
+		query.Set("page", strconv.Itoa(offset/limit+1))
+		query.Set("per_page", strconv.Itoa(limit))
+	}
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
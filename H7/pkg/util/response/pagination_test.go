@@ -0,0 +1,28 @@
+package response
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaginationPageLinks(t *testing.T) {
+	asserts := assert.New(t)
+	requestURL, _ := url.Parse("/api/v1/books?page=2&per_page=10&sort=title")
+
+	p := NewPagination(requestURL, 10, 10, 35)
+	asserts.Equal(2, p.Page)
+	asserts.Equal(4, p.PageCount)
+	asserts.Equal("/api/v1/books?page=3&per_page=10&sort=title", p.Next)
+	asserts.Equal("/api/v1/books?page=1&per_page=10&sort=title", p.Prev)
+}
+
+func TestNewPaginationOffsetLinks(t *testing.T) {
+	asserts := assert.New(t)
+	requestURL, _ := url.Parse("/api/v1/books?limit=20&offset=5")
+
+	p := NewPagination(requestURL, 5, 20, 25)
+	asserts.Empty(p.Next)
+	asserts.Equal("/api/v1/books?limit=20&offset=0", p.Prev)
+}
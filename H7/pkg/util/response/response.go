@@ -22,9 +22,10 @@ func (e *ErrorResponse) SendErrorResponse(c echo.Context) error {
 }
 
 type SuccessResponse struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Code       int         `json:"code"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
 }
 
 func NewSuccessResponse(code int, message string, data interface{}) *SuccessResponse {
@@ -35,10 +36,20 @@ func NewSuccessResponse(code int, message string, data interface{}) *SuccessResp
 	}
 }
 
+// WithPagination attaches the paging metadata of a listing to the envelope.
+func (s *SuccessResponse) WithPagination(pagination *Pagination) *SuccessResponse {
+	s.Pagination = pagination
+	return s
+}
+
 func (s *SuccessResponse) SendSuccessResponse(c echo.Context) error {
-	return c.JSON(s.Code, map[string]interface{}{
+	body := map[string]interface{}{
 		"message": s.Message,
 		"code":    s.Code,
 		"data":    s.Data,
-	})
+	}
+	if s.Pagination != nil {
+		body["pagination"] = s.Pagination
+	}
+	return c.JSON(s.Code, body)
 }
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+type tokenSpan struct {
+	start, end int
+	token      string
+}
+
+// Tokenize splits text into lower cased words made of letters and digits.
+func Tokenize(text string) []string {
+	tokens := []string{}
+	for _, span := range tokenSpans([]rune(text)) {
+		tokens = append(tokens, span.token)
+	}
+	return tokens
+}
+
+func tokenSpans(runes []rune) []tokenSpan {
+	spans := []tokenSpan{}
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		isWord := i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, tokenSpan{start, i, strings.ToLower(string(runes[start:i]))})
+			start = -1
+		}
+	}
+	return spans
+}
+
+// Highlight returns an HTML escaped excerpt of about width characters around
+// the first word matching one of terms, with every matching word wrapped in
+// <em>. It returns an empty string when nothing matches.
+func Highlight(text string, terms []string, width int) string {
+	wanted := map[string]bool{}
+	for _, term := range terms {
+		wanted[strings.ToLower(term)] = true
+	}
+
+	runes := []rune(text)
+	spans := tokenSpans(runes)
+	first := -1
+	for _, span := range spans {
+		if wanted[span.token] {
+			first = span.start
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start := first - width/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+	}
+	// never cut a word in half at either end of the excerpt
+	for _, span := range spans {
+		if span.start < start && span.end > start {
+			start = span.start
+		}
+		if span.start < end && span.end > end {
+			end = span.start
+		}
+	}
+
+	var b strings.Builder
+	pos := start
+	for _, span := range spans {
+		if span.start < start || span.end > end || !wanted[span.token] {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:span.start])))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(string(runes[span.start:span.end])))
+		b.WriteString("</em>")
+		pos = span.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+
+	excerpt := strings.TrimSpace(b.String())
+	if start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(runes) {
+		excerpt += "…"
+	}
+	return excerpt
+}
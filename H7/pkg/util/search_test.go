@@ -0,0 +1,21 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	asserts := assert.New(t)
+	asserts.Equal([]string{"the", "lord", "of", "the", "rings", "1954"}, Tokenize("The Lord of the Rings (1954)"))
+	asserts.Empty(Tokenize(" -- "))
+}
+
+func TestHighlight(t *testing.T) {
+	asserts := assert.New(t)
+
+	asserts.Equal("The Lord of the <em>Rings</em> &amp; <em>rings</em>", Highlight("The Lord of the Rings & rings", []string{"rings"}, 100))
+	asserts.Equal("…brown <em>fox</em> jumps over…", Highlight("over the quick brown fox jumps over the lazy dog", []string{"fox"}, 20))
+	asserts.Empty(Highlight("The Hobbit", []string{"rings"}, 100))
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	asserts := assert.New(t)
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	// RFC 6238 appendix B, truncated to six digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		if asserts.NoError(err) {
+			asserts.Equal(expected, code)
+		}
+	}
+}
+
+func TestValidateTOTPAllowsOneStepDrift(t *testing.T) {
+	asserts := assert.New(t)
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	previous, err := TOTPCode(secret, TOTPStep(now)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, ok := ValidateTOTP(secret, previous, now)
+	asserts.True(ok)
+	asserts.Equal(TOTPStep(now)-1, step)
+
+	stale, err := TOTPCode(secret, TOTPStep(now)-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok = ValidateTOTP(secret, stale, now)
+	asserts.False(ok)
+}
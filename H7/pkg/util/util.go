@@ -1,6 +1,10 @@
 package util
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 func Getenv(key, fallback string) string {
 	var (
@@ -13,3 +17,27 @@ func Getenv(key, fallback string) string {
 	}
 	return val
 }
+
+func GetenvDuration(key string, fallback time.Duration) time.Duration {
+	val, isExist := os.LookupEnv(key)
+	if !isExist {
+		return fallback
+	}
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
+
+func GetenvInt(key string, fallback int) int {
+	val, isExist := os.LookupEnv(key)
+	if !isExist {
+		return fallback
+	}
+	number, err := strconv.Atoi(val)
+	if err != nil {
+		return fallback
+	}
+	return number
+}
@@ -11,6 +11,16 @@ type CustomValidator struct {
 	Validator *validator.Validate
 }
 
+// NewValidator returns a validator with the tags this API adds on top of the
+// built-in ones. isbn replaces the built-in tag so hyphenated ISBNs pass.
+func NewValidator() *CustomValidator {
+	v := validator.New()
+	v.RegisterValidation("isbn", func(fl validator.FieldLevel) bool {
+		return IsValidISBN(fl.Field().String())
+	})
+	return &CustomValidator{Validator: v}
+}
+
 func (cv *CustomValidator) Validate(i interface{}) error {
 	if err := cv.Validator.Struct(i); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())